@@ -0,0 +1,103 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/cli"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/nxiq"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/util"
+)
+
+// auditPoliciesCommand compares the policies that apply to Proxy Repositories with the Reference Policy Set,
+// without downloading anything
+func auditPoliciesCommand(args []string) {
+	flags := newFlagSet("audit-policies")
+	nexusURL := flags.String("nxrm-url", "", "Sonatype Nexus Repository URL (e.g. https://nexus.example.com) - not needed with --nxiq-url")
+	nxiqURL := flags.String("nxiq-url", "", "Sonatype IQ Server URL (defaults to the IQ Server connected to Sonatype Nexus Repository)")
+	policyMapping := make(formats.PolicyMapping)
+	flags.Var(policyNameSpecs{mapping: policyMapping}, "policy-name", "Name of a Sonatype IQ Server policy that takes the place of a Reference Policy, as <policy>=<name> - may be repeated")
+	flags.Var(policyNameSpecs{mapping: policyMapping, pattern: true}, "policy-pattern", "Regular expression matching Sonatype IQ Server policies that take the place of a Reference Policy, as <policy>=<regex> - may be repeated")
+	parseFlags(flags, args)
+
+	// Sonatype Nexus Repository is only needed to find the IQ Server when --nxiq-url is not given
+	var nxiqConnection *nxiq.NxiqConnection
+	if iqURL := strings.TrimSuffix(*nxiqURL, "/"); iqURL != "" {
+		nxiqConnection = connectNxiq(nil, iqURL, requireNxiqCredentials(credentials{}))
+	} else {
+		creds := requireCredentials(credentials{}, true)
+		nxiqConnection = connectNxiq(connectNxrm(resolveNexusURL(*nexusURL), creds), "", creds)
+	}
+	nxiqConnection.SetPolicyMapping(policyMapping)
+
+	policies, err := nxiqConnection.GetRepositoryPolicies()
+	if err != nil {
+		cli.PrintCliln(fmt.Sprintf("Error: %v", err), util.ColorRed)
+		os.Exit(exitRunError)
+	}
+	findings := nxiqConnection.AuditPolicies(policies)
+
+	cli.PrintCliln("\n=== Reference Policy Set Audit ===\n", util.ColorYellow)
+	byReference := make(map[formats.PolicyName][]nxiq.Finding)
+	for _, finding := range findings {
+		byReference[finding.Reference] = append(byReference[finding.Reference], finding)
+	}
+	for _, reference := range formats.AllPolicyNames {
+		if reference == formats.None {
+			continue
+		}
+		if len(byReference[reference]) == 0 {
+			cli.PrintCliln(fmt.Sprintf("✓ %s", reference), util.ColorGreen)
+			continue
+		}
+		for _, finding := range byReference[reference] {
+			color := util.ColorYellow
+			if finding.Kind.LetsPackagesThrough() {
+				color = util.ColorRed
+			}
+			subject := string(reference)
+			if finding.Policy != "" && finding.Policy != subject {
+				subject = fmt.Sprintf("%s (%s)", reference, finding.Policy)
+			}
+			cli.PrintCliln(fmt.Sprintf("✗ %s [%s]: %s", subject, finding.Kind, finding.Detail), color)
+		}
+	}
+
+	cli.PrintCliln("", util.ColorReset)
+	exitCode := exitOK
+	letThrough := 0
+	for _, finding := range findings {
+		if finding.Kind.LetsPackagesThrough() {
+			letThrough++
+		}
+	}
+	switch {
+	case letThrough > 0:
+		cli.PrintCliln(fmt.Sprintf("✗ %d difference(s) from the Reference Policy Set could let test packages be downloaded", letThrough), util.ColorRed)
+		exitCode = exitPolicyGap
+	case len(findings) > 0:
+		cli.PrintCliln(fmt.Sprintf("✗ %d difference(s) from the Reference Policy Set", len(findings)), util.ColorYellow)
+		exitCode = exitPolicyMismatch
+	default:
+		cli.PrintCliln("✓ Policies match the Reference Policy Set", util.ColorGreen)
+	}
+	os.Exit(exitCode)
+}
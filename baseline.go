@@ -0,0 +1,96 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/cli"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/report"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/util"
+)
+
+// baselineSections lists the kinds of change in the order they are displayed, with their heading and colour
+var baselineSections = []struct {
+	kind    report.ChangeKind
+	heading string
+	color   string
+}{
+	{report.ChangeNewlyAvailable, "Newly available - Firewall protection lost", util.ColorRed},
+	{report.ChangePolicyChanged, "Quarantined by different policies", util.ColorMagenta},
+	{report.ChangeNewlyQuarantined, "Newly quarantined", util.ColorCyan},
+	{report.ChangeStatusChanged, "Other status changes", util.ColorYellow},
+	{report.ChangeDisappeared, "No longer in the catalog", util.ColorYellow},
+	{report.ChangeAdded, "New to the catalog", util.ColorBlue},
+}
+
+// displayBaselineComparison displays the differences between this run and the baseline
+func displayBaselineComparison(comparison *report.Comparison) {
+	cli.PrintCliln("", util.ColorBlue)
+	cli.PrintCliln("========================= Baseline Comparison ==========================", util.ColorYellow)
+	cli.PrintCliln(fmt.Sprintf("Baseline run started: %s", comparison.BaselineStartedAt.Local().Format("2006-01-02 15:04:05")), util.ColorReset)
+
+	if len(comparison.Changes) == 0 {
+		cli.PrintCliln("✓ No changes since the baseline", util.ColorGreen)
+		return
+	}
+
+	for _, section := range baselineSections {
+		if comparison.Count(section.kind) == 0 {
+			continue
+		}
+		cli.PrintCliln(fmt.Sprintf("\n%s (%d)", section.heading, comparison.Count(section.kind)), section.color)
+		for _, change := range comparison.Changes {
+			if change.Kind != section.kind {
+				continue
+			}
+			cli.PrintCliln(
+				fmt.Sprintf(
+					"  %s/%s: %s [%s]%s",
+					change.Format,
+					change.Repository,
+					change.Package,
+					change.ExpectedPolicy,
+					describeChange(change),
+				),
+				section.color,
+			)
+		}
+	}
+}
+
+// describeChange summarises the before and after of a change
+func describeChange(change report.Change) string {
+	switch change.Kind {
+	case report.ChangePolicyChanged:
+		return fmt.Sprintf(" - %s → %s", policyList(change.PreviousPolicies), policyList(change.CurrentPolicies))
+	case report.ChangeDisappeared:
+		return fmt.Sprintf(" - was %s", change.PreviousStatus)
+	case report.ChangeAdded:
+		return fmt.Sprintf(" - %s", change.CurrentStatus)
+	default:
+		return fmt.Sprintf(" - %s → %s", change.PreviousStatus, change.CurrentStatus)
+	}
+}
+
+func policyList(policies []string) string {
+	if len(policies) == 0 {
+		return "none"
+	}
+	return strings.Join(policies, ", ")
+}
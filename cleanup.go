@@ -0,0 +1,84 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/cli"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/util"
+)
+
+// exitCodeInterrupted is the conventional exit code for a process stopped by Ctrl-C
+const exitCodeInterrupted = 130
+
+var (
+	cleanupMutex sync.Mutex
+	cleanups     []func()
+	cleanupOnce  sync.Once
+)
+
+// onExit registers a function to run before the tool exits through exit, or when it is interrupted
+func onExit(cleanup func()) {
+	cleanupMutex.Lock()
+	defer cleanupMutex.Unlock()
+	cleanups = append(cleanups, cleanup)
+}
+
+// runCleanups runs every registered cleanup, most recent first. Cleanups only run once - a second call waits
+// for the first to finish
+func runCleanups() {
+	cleanupOnce.Do(func() {
+		cleanupMutex.Lock()
+		registered := cleanups
+		cleanups = nil
+		cleanupMutex.Unlock()
+
+		for i := len(registered) - 1; i >= 0; i-- {
+			registered[i]()
+		}
+	})
+}
+
+// exit runs the registered cleanups, then exits with the code
+func exit(code int) {
+	runCleanups()
+	os.Exit(code)
+}
+
+// cleanupOnPanic runs the registered cleanups if the calling function panics, then carries on panicking - defer
+// it in commands that register cleanups
+func cleanupOnPanic() {
+	if r := recover(); r != nil {
+		runCleanups()
+		panic(r)
+	}
+}
+
+// handleInterrupts runs the registered cleanups when the tool is interrupted (Ctrl-C) or terminated
+func handleInterrupts() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cli.PrintCliln("\nInterrupted - cleaning up...", util.ColorYellow)
+		exit(exitCodeInterrupted)
+	}()
+}
@@ -0,0 +1,162 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/nxrm"
+)
+
+// resetCleanups forgets registered cleanups, so each test starts afresh
+func resetCleanups(t *testing.T) {
+	t.Helper()
+	cleanupMutex.Lock()
+	defer cleanupMutex.Unlock()
+	cleanups = nil
+	cleanupOnce = sync.Once{}
+}
+
+func TestRunCleanups(t *testing.T) {
+	resetCleanups(t)
+	var ran []string
+	onExit(func() { ran = append(ran, "first") })
+	onExit(func() { ran = append(ran, "second") })
+
+	runCleanups()
+	runCleanups()
+	if want := []string{"second", "first"}; !slices.Equal(ran, want) {
+		t.Errorf("cleanups ran %v, want %v", ran, want)
+	}
+}
+
+func TestRunCleanupsConcurrently(t *testing.T) {
+	resetCleanups(t)
+	var mu sync.Mutex
+	count := 0
+	onExit(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		count++
+	})
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runCleanups()
+		}()
+	}
+	wg.Wait()
+	if count != 1 {
+		t.Errorf("cleanup ran %d times, want 1", count)
+	}
+}
+
+func TestCleanupOnPanic(t *testing.T) {
+	resetCleanups(t)
+	ran := false
+	onExit(func() { ran = true })
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("recovered %v, want the original panic", r)
+		}
+		if !ran {
+			t.Error("cleanup did not run on panic")
+		}
+	}()
+	func() {
+		defer cleanupOnPanic()
+		panic("boom")
+	}()
+}
+
+func TestProvisionTargetsCleanup(t *testing.T) {
+	resetCleanups(t)
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/status"):
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodPost && strings.Contains(r.URL.Path, "/repositories/"):
+			requests = append(requests, "create "+strings.TrimPrefix(r.URL.Path, "/service/rest/v1/repositories/"))
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodDelete:
+			requests = append(requests, "delete "+r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	connection, err := nxrm.NewNxrmConnection(server.URL, "admin", "admin123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	targets := provisionTargets([]formats.PackageFormat{formats.NPMFormat{}, formats.PyPIFormat{}}, "default", false, connection)
+	if len(targets) != 2 {
+		t.Fatalf("provisioned %d targets, want 2", len(targets))
+	}
+	if len(requests) != 2 {
+		t.Fatalf("requests before cleanup = %v, want two creates", requests)
+	}
+
+	runCleanups()
+	want := []string{
+		"create npm/proxy",
+		"create pypi/proxy",
+		"delete " + targets[1].repoName,
+		"delete " + targets[0].repoName,
+	}
+	if !slices.Equal(requests, want) {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+}
+
+func TestProvisionTargetsKept(t *testing.T) {
+	resetCleanups(t)
+	deleted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deleted = true
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	connection, err := nxrm.NewNxrmConnection(server.URL, "admin", "admin123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	provisionTargets([]formats.PackageFormat{formats.NPMFormat{}}, "default", true, connection)
+	runCleanups()
+	if deleted {
+		t.Error("a kept repository was deleted")
+	}
+}
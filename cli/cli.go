@@ -60,3 +60,14 @@ func ReadInput(prompt string) string {
 	input, _ := reader.ReadString('\n')
 	return strings.TrimSpace(input)
 }
+
+// FindFormat returns the format matching the given name or display name (case-insensitive)
+func FindFormat(possibleFormats []formats.PackageFormat, name string) (formats.PackageFormat, error) {
+	for _, format := range possibleFormats {
+		if strings.EqualFold(format.GetName(), name) || strings.EqualFold(format.GetDisplayName(), name) {
+			return format, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported format '%s'", name)
+}
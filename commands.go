@@ -0,0 +1,334 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/cli"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/notify"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/nxiq"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/nxrm"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/report"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/util"
+)
+
+// command represents a sub-command of this tool
+type command struct {
+	name        string
+	description string
+	run         func(args []string)
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{name: "run", description: "Check packages against a Proxy Repository (default)", run: runCommand},
+		{name: "list-formats", description: "List supported package formats", run: listFormatsCommand},
+		{name: "list-repos", description: "List Proxy Repositories available for testing", run: listReposCommand},
+		{name: "list-packages", description: "List the test packages for a format", run: listPackagesCommand},
+		{name: "audit-policies", description: "Compare Sonatype IQ Server policies with the Reference Policy Set, without downloading", run: auditPoliciesCommand},
+		{name: "test-waiver", description: "Waive a quarantined test package, check it becomes downloadable, then delete the waiver", run: waiverCommand},
+		{name: "matrix", description: "Show which Reference Policies can be tested for each format", run: matrixCommand},
+		{name: "history", description: "List, show and chart the trend of previous runs (list, show, trend)", run: historyCommand},
+		{name: "help", description: "Show this help", run: func(args []string) { printUsage() }},
+	}
+}
+
+// findCommand returns the command with the given name, or nil
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// printUsage outputs the available commands
+func printUsage() {
+	fmt.Println("Usage: nxfw-policy-tester [command] [flags]")
+	fmt.Println("\nCommands:")
+	for _, cmd := range commands {
+		fmt.Printf("  %-15s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Println("\nRun 'nxfw-policy-tester <command> -h' for the flags each command accepts.")
+}
+
+// newFlagSet creates a FlagSet for the named command
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: nxfw-policy-tester %s [flags]\n\nFlags:\n", name)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// outputSpec is a report to write, given on the command line as <format>=<file>
+type outputSpec struct {
+	format string
+	path   string
+}
+
+// outputSpecs collects repeated --output flags
+type outputSpecs []outputSpec
+
+func (o *outputSpecs) String() string {
+	var specs []string
+	for _, spec := range *o {
+		specs = append(specs, spec.format+"="+spec.path)
+	}
+	return strings.Join(specs, ",")
+}
+
+func (o *outputSpecs) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return fmt.Errorf("expected <format>=<file>, got '%s'", value)
+	}
+	if _, err := report.FindReporter(parts[0]); err != nil {
+		return err
+	}
+	*o = append(*o, outputSpec{format: parts[0], path: parts[1]})
+	return nil
+}
+
+// notifySpecs collects repeated --notify flags, given as <type>=<url>
+type notifySpecs []notify.Target
+
+func (n *notifySpecs) String() string {
+	var specs []string
+	for _, target := range *n {
+		specs = append(specs, string(target.Kind)+"="+target.URL)
+	}
+	return strings.Join(specs, ",")
+}
+
+func (n *notifySpecs) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return fmt.Errorf("expected <type>=<url>, got '%s'", value)
+	}
+	kind, err := notify.ParseKind(parts[0])
+	if err != nil {
+		return err
+	}
+	*n = append(*n, notify.Target{Kind: kind, URL: parts[1], When: notify.WhenAlways})
+	return nil
+}
+
+// expectSpecs collects repeated --expect flags, given as <policy>=<action>
+type expectSpecs map[formats.PolicyName]formats.ExpectedAction
+
+func (e expectSpecs) String() string {
+	var specs []string
+	for policy, action := range e {
+		specs = append(specs, string(policy)+"="+string(action))
+	}
+	return strings.Join(specs, ",")
+}
+
+func (e expectSpecs) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("expected <policy>=<action>, got '%s'", value)
+	}
+
+	policy := formats.PolicyName(parts[0])
+	if !slices.Contains(formats.AllPolicyNames, policy) {
+		return fmt.Errorf("unknown Reference Policy '%s'", parts[0])
+	}
+	action := formats.ExpectedAction(parts[1])
+	if !slices.Contains(formats.AllExpectedActions, action) {
+		return fmt.Errorf("unknown expected action '%s' - expected quarantine, allow or warn-only", parts[1])
+	}
+
+	e[policy] = action
+	return nil
+}
+
+// policyNameSpecs collects repeated --policy-name and --policy-pattern flags, given as <policy>=<name>
+type policyNameSpecs struct {
+	mapping formats.PolicyMapping
+	pattern bool
+}
+
+func (p policyNameSpecs) String() string {
+	var specs []string
+	for policy, names := range p.mapping.Describe() {
+		specs = append(specs, policy+"="+strings.Join(names, "|"))
+	}
+	return strings.Join(specs, ",")
+}
+
+func (p policyNameSpecs) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return fmt.Errorf("expected <policy>=<name>, got '%s'", value)
+	}
+
+	policy := formats.PolicyName(parts[0])
+	if !slices.Contains(formats.AllPolicyNames, policy) {
+		return fmt.Errorf("unknown Reference Policy '%s'", parts[0])
+	}
+
+	aliases := p.mapping[policy]
+	if p.pattern {
+		pattern, err := regexp.Compile(parts[1])
+		if err != nil {
+			return fmt.Errorf("invalid regular expression: %w", err)
+		}
+		aliases.Patterns = append(aliases.Patterns, pattern)
+	} else {
+		aliases.Names = append(aliases.Names, parts[1])
+	}
+	p.mapping[policy] = aliases
+	return nil
+}
+
+// parseFlags parses the arguments, exiting if they are invalid or help was requested
+func parseFlags(flags *flag.FlagSet, args []string) {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(exitOK)
+		}
+		os.Exit(exitRunError)
+	}
+}
+
+// resolveNexusURL returns the Nexus Repository URL, prompting for it when not supplied
+func resolveNexusURL(nexusURL string) string {
+	if nexusURL == "" {
+		fmt.Printf("\n%sEnter your Sonatype Nexus Repository URL:%s\n", util.ColorYellow, util.ColorReset)
+		fmt.Println("(Example: https://nexus.example.com)")
+		nexusURL = cli.ReadInput("")
+	}
+	nexusURL = strings.TrimSuffix(nexusURL, "/")
+
+	// Validate URL
+	if !strings.HasPrefix(nexusURL, "http://") && !strings.HasPrefix(nexusURL, "https://") {
+		fmt.Printf("%sError: Invalid URL format. URL must start with http:// or https://%s\n", util.ColorRed, util.ColorReset)
+		os.Exit(exitRunError)
+	}
+
+	return nexusURL
+}
+
+// resolveFormat returns the named format, prompting for it when not supplied
+func resolveFormat(formatName string) formats.PackageFormat {
+	if formatName == "" {
+		return cli.PromptSelectFormat(allSupportedFormats)
+	}
+
+	format, err := cli.FindFormat(allSupportedFormats, formatName)
+	if err != nil {
+		cli.PrintCliln(fmt.Sprintf("Error: %v", err), util.ColorRed)
+		os.Exit(exitRunError)
+	}
+	return format
+}
+
+// credentials holds usernames and passwords obtained from environment variables
+type credentials struct {
+	nxrmUsername string
+	nxrmPassword string
+	nxiqUsername string
+	nxiqPassword string
+}
+
+// requireCredentials fills any credentials not already set from environment variables, exiting if any required are missing
+func requireCredentials(creds credentials, needNxiq bool) credentials {
+	if creds.nxrmUsername == "" {
+		creds.nxrmUsername = os.Getenv("NXRM_USERNAME")
+	}
+	if creds.nxrmPassword == "" {
+		creds.nxrmPassword = os.Getenv("NXRM_PASSWORD")
+	}
+	if creds.nxrmUsername == "" || creds.nxrmPassword == "" {
+		fmt.Printf("%sError: NXRM_USERNAME and NXRM_PASSWORD environment variables must be set.%s\n", util.ColorRed, util.ColorReset)
+		fmt.Println("Example: export NXRM_USERNAME='your_username'")
+		fmt.Println("         export NXRM_PASSWORD='your_password'")
+		os.Exit(exitRunError)
+	}
+
+	if needNxiq {
+		return requireNxiqCredentials(creds)
+	}
+	return creds
+}
+
+// requireNxiqCredentials fills the Sonatype IQ Server credentials not already set from environment variables,
+// exiting if either is missing
+func requireNxiqCredentials(creds credentials) credentials {
+	if creds.nxiqUsername == "" {
+		creds.nxiqUsername = os.Getenv("NXIQ_USERNAME")
+	}
+	if creds.nxiqPassword == "" {
+		creds.nxiqPassword = os.Getenv("NXIQ_PASSWORD")
+	}
+
+	if creds.nxiqUsername == "" || creds.nxiqPassword == "" {
+		fmt.Printf("%sError: NXIQ_PASSWORD and NXIQ_USERNAME environment variables must be set.%s\n", util.ColorRed, util.ColorReset)
+		fmt.Println("Example: export NXIQ_PASSWORD='your_username'")
+		fmt.Println("         export NXIQ_USERNAME='your_password'")
+		os.Exit(exitRunError)
+	}
+
+	return creds
+}
+
+// connectNxrm authenticates with Sonatype Nexus Repository
+func connectNxrm(nexusURL string, creds credentials) *nxrm.NxrmConnection {
+	nxrmConnection, err := nxrm.NewNxrmConnection(nexusURL, creds.nxrmUsername, creds.nxrmPassword)
+	if err != nil {
+		os.Exit(exitRunError)
+	}
+
+	cli.PrintCliln("✓ Successfully authenticated with Sonatype Nexus Repository", util.ColorGreen)
+
+	return nxrmConnection
+}
+
+// connectNxiq authenticates with Sonatype IQ Server - if nxiqUrl is empty, the IQ Server connected to
+// Sonatype Nexus Repository is used, otherwise nxrmConnection may be nil
+func connectNxiq(nxrmConnection *nxrm.NxrmConnection, nxiqUrl string, creds credentials) *nxiq.NxiqConnection {
+	// Get IQ URL
+	if nxiqUrl == "" {
+		var err error
+		nxiqUrl, err = nxrmConnection.GetConnectedIqServer()
+		if err != nil {
+			os.Exit(exitRunError)
+		}
+	}
+
+	nxiqConnection, err := nxiq.NewNxiqConnection(nxiqUrl, creds.nxiqUsername, creds.nxiqPassword)
+	if err != nil {
+		os.Exit(exitRunError)
+	}
+
+	cli.PrintCliln(fmt.Sprintf("✓ Successfully authenticated with Sonatype IQ Server (%s)", nxiqUrl), util.ColorGreen)
+
+	return nxiqConnection
+}
@@ -0,0 +1,498 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/notify"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaURL is where the published JSON Schema for run configuration files can be found
+const SchemaURL = "https://raw.githubusercontent.com/sonatype-nexus-community/nxfw-policy-tester/main/config/schema.json"
+
+//go:embed schema.json
+var schemaJSON string
+
+var (
+	envReference      = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	unknownProperties = regexp.MustCompile(`^additionalProperties (.+) not allowed$`)
+	quotedName        = regexp.MustCompile(`'([^']+)'`)
+)
+
+// RunConfig describes a complete run - loaded from a YAML or JSON file
+type RunConfig struct {
+	Nxrm             ServerConfig                   `yaml:"nxrm"`
+	Iq               ServerConfig                   `yaml:"iq"`
+	Targets          []TargetConfig                 `yaml:"targets"`
+	Output           OutputConfig                   `yaml:"output"`
+	Notifications    []NotificationConfig           `yaml:"notifications"`
+	Expectations     ExpectationsConfig             `yaml:"expectations"`
+	PolicyMapping    map[string]PolicyAliasesConfig `yaml:"policyMapping"`
+	DiscoverPolicies bool                           `yaml:"discoverPolicies"`
+	PurgeCache       bool                           `yaml:"purgeCache"`
+
+	policyMapping formats.PolicyMapping
+}
+
+// PolicyAliasesConfig lists the Sonatype IQ Server policies that take the place of a Reference Policy
+type PolicyAliasesConfig struct {
+	Names    []string `yaml:"names"`
+	Patterns []string `yaml:"patterns"`
+}
+
+// GetPolicyMapping returns the compiled mapping of Reference Policies to Sonatype IQ Server policies
+func (c *RunConfig) GetPolicyMapping() formats.PolicyMapping {
+	return c.policyMapping
+}
+
+// ServerConfig holds the URL and (optional) credentials for a server
+type ServerConfig struct {
+	URL      string `yaml:"url"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// TargetConfig is a format and the Proxy Repositories to test it against
+type TargetConfig struct {
+	Format       string              `yaml:"format"`
+	Repositories []string            `yaml:"repositories"`
+	Packages     PackageFilterConfig `yaml:"packages"`
+
+	filter formats.PackageFilter
+}
+
+// PackageFilterConfig restricts which test packages are checked for a target
+type PackageFilterConfig struct {
+	Policies []string `yaml:"policies"`
+	Include  []string `yaml:"include"`
+	Exclude  []string `yaml:"exclude"`
+}
+
+// OutputConfig holds output settings
+type OutputConfig struct {
+	Color   *bool          `yaml:"color"`
+	Reports []ReportConfig `yaml:"reports"`
+}
+
+// ReportConfig is a report to write once the run completes
+type ReportConfig struct {
+	Format string `yaml:"format"`
+	Path   string `yaml:"path"`
+}
+
+// ExpectationsConfig overrides what Sonatype Repository Firewall is expected to do with test packages
+type ExpectationsConfig struct {
+	Policies map[string]string          `yaml:"policies"`
+	Packages []PackageExpectationConfig `yaml:"packages"`
+}
+
+// PackageExpectationConfig overrides the expected action for matching packages
+type PackageExpectationConfig struct {
+	Format  string `yaml:"format"`
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+	Policy  string `yaml:"policy"`
+	Action  string `yaml:"action"`
+}
+
+// Expectations returns the configured expectations
+func (e ExpectationsConfig) Expectations() formats.Expectations {
+	expectations := formats.Expectations{Policies: make(map[formats.PolicyName]formats.ExpectedAction)}
+	for policy, action := range e.Policies {
+		expectations.Policies[formats.PolicyName(policy)] = formats.ExpectedAction(action)
+	}
+	for _, p := range e.Packages {
+		expectations.Packages = append(expectations.Packages, formats.PackageExpectation{
+			Format:  p.Format,
+			Name:    p.Name,
+			Version: p.Version,
+			Policy:  formats.PolicyName(p.Policy),
+			Action:  formats.ExpectedAction(p.Action),
+		})
+	}
+	return expectations
+}
+
+// NotificationConfig is somewhere to send a summary once the run completes
+type NotificationConfig struct {
+	Type        string `yaml:"type"`
+	URL         string `yaml:"url"`
+	When        string `yaml:"when"`
+	Template    string `yaml:"template"`
+	ContentType string `yaml:"contentType"`
+
+	target notify.Target
+}
+
+// Target returns the parsed notification target
+func (n NotificationConfig) Target() notify.Target {
+	return n.target
+}
+
+// PackageFilter returns the compiled package filter for this target
+func (t TargetConfig) PackageFilter() formats.PackageFilter {
+	return t.filter
+}
+
+// UseColor returns whether terminal output should be coloured
+func (o OutputConfig) UseColor() bool {
+	return o.Color == nil || *o.Color
+}
+
+// Error describes a problem with a specific key in a configuration file
+type Error struct {
+	File    string
+	Line    int
+	Key     string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s: %s", e.File, e.Line, e.Key, e.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", e.File, e.Key, e.Message)
+}
+
+// Load reads a run configuration file, expands ${ENV} references and validates it against the schema
+func Load(path string) (*RunConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(root.Content) == 0 {
+		return nil, &Error{File: path, Key: "(root)", Message: "configuration is empty"}
+	}
+	document := root.Content[0]
+
+	// Expand environment variables
+	if errs := expandEnv(document, nil); len(errs) > 0 {
+		return nil, withFile(path, errs)
+	}
+
+	// Validate against the schema
+	if errs := validateSchema(document); len(errs) > 0 {
+		return nil, withFile(path, errs)
+	}
+
+	var cfg RunConfig
+	if err := document.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	// Compile package filters
+	var errs []*Error
+	for i := range cfg.Targets {
+		target := &cfg.Targets[i]
+		for _, p := range target.Packages.Policies {
+			target.filter.Policies = append(target.filter.Policies, formats.PolicyName(p))
+		}
+
+		var compileErrs []*Error
+		target.filter.Include, compileErrs = compilePatterns(document, target.Packages.Include, "targets", strconv.Itoa(i), "packages", "include")
+		errs = append(errs, compileErrs...)
+		target.filter.Exclude, compileErrs = compilePatterns(document, target.Packages.Exclude, "targets", strconv.Itoa(i), "packages", "exclude")
+		errs = append(errs, compileErrs...)
+	}
+
+	// Compile policy mapping patterns
+	cfg.policyMapping = make(formats.PolicyMapping)
+	for reference, aliases := range cfg.PolicyMapping {
+		patterns, compileErrs := compilePatterns(document, aliases.Patterns, "policyMapping", reference, "patterns")
+		errs = append(errs, compileErrs...)
+		cfg.policyMapping[formats.PolicyName(reference)] = formats.PolicyAliases{Names: aliases.Names, Patterns: patterns}
+	}
+
+	// Parse notification templates
+	for i := range cfg.Notifications {
+		notification := &cfg.Notifications[i]
+		notification.target = notify.Target{
+			Kind:        notify.Kind(notification.Type),
+			URL:         notification.URL,
+			When:        notify.WhenAlways,
+			ContentType: notification.ContentType,
+		}
+		if notification.When != "" {
+			notification.target.When = notify.When(notification.When)
+		}
+		if notification.Template != "" {
+			tmpl, err := notify.ParseTemplate(notification.Template)
+			if err != nil {
+				tokens := []string{"notifications", strconv.Itoa(i), "template"}
+				errs = append(errs, &Error{
+					Line:    lineOf(document, tokens),
+					Key:     keyPath(tokens),
+					Message: fmt.Sprintf("invalid template: %v", err),
+				})
+				continue
+			}
+			notification.target.Template = tmpl
+		}
+	}
+	if len(errs) > 0 {
+		return nil, withFile(path, errs)
+	}
+
+	return &cfg, nil
+}
+
+// compilePatterns compiles regular expressions found at the given location in the document
+func compilePatterns(document *yaml.Node, patterns []string, location ...string) ([]*regexp.Regexp, []*Error) {
+	var compiled []*regexp.Regexp
+	var errs []*Error
+	for i, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			tokens := append(location, strconv.Itoa(i))
+			errs = append(errs, &Error{
+				Line:    lineOf(document, tokens),
+				Key:     keyPath(tokens),
+				Message: fmt.Sprintf("invalid regular expression: %v", err),
+			})
+			continue
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, errs
+}
+
+// expandEnv replaces ${ENV} references in all scalar values
+func expandEnv(node *yaml.Node, tokens []string) []*Error {
+	var errs []*Error
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			errs = append(errs, expandEnv(node.Content[i+1], append(tokens, node.Content[i].Value))...)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			errs = append(errs, expandEnv(child, append(tokens, strconv.Itoa(i)))...)
+		}
+	case yaml.ScalarNode:
+		if !envReference.MatchString(node.Value) {
+			break
+		}
+		node.Value = envReference.ReplaceAllStringFunc(node.Value, func(ref string) string {
+			name := envReference.FindStringSubmatch(ref)[1]
+			value, ok := os.LookupEnv(name)
+			if !ok {
+				errs = append(errs, &Error{
+					Line:    node.Line,
+					Key:     keyPath(tokens),
+					Message: fmt.Sprintf("environment variable %s is not set", name),
+				})
+			}
+			return value
+		})
+		// Substituted values remain strings, unless unquoted where the schema expects another type
+		switch schemaType(tokens) {
+		case "boolean", "integer", "number":
+			if node.Style == 0 {
+				node.Tag = ""
+			}
+		}
+	}
+	return errs
+}
+
+// schemaType returns the type the schema gives the value at the key path, or "" if it gives none
+func schemaType(tokens []string) string {
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(schemaJSON), &schema); err != nil {
+		return ""
+	}
+	defs, _ := schema["$defs"].(map[string]interface{})
+	resolve := func(node interface{}) map[string]interface{} {
+		object, _ := node.(map[string]interface{})
+		if ref, ok := object["$ref"].(string); ok {
+			object, _ = defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{})
+		}
+		return object
+	}
+
+	node := schema
+	for _, token := range tokens {
+		properties, _ := node["properties"].(map[string]interface{})
+		switch {
+		case properties[token] != nil:
+			node = resolve(properties[token])
+		case node["items"] != nil:
+			node = resolve(node["items"])
+		default:
+			node = resolve(node["additionalProperties"])
+		}
+		if node == nil {
+			return ""
+		}
+	}
+	schemaType, _ := node["type"].(string)
+	return schemaType
+}
+
+// validateSchema validates the document against the published JSON Schema
+func validateSchema(document *yaml.Node) []*Error {
+	schema, err := jsonschema.CompileString(SchemaURL, schemaJSON)
+	if err != nil {
+		return []*Error{{Key: "(schema)", Message: err.Error()}}
+	}
+
+	// Round-trip via JSON so the validator sees JSON types
+	var raw interface{}
+	if err := document.Decode(&raw); err != nil {
+		return []*Error{{Line: document.Line, Key: "(root)", Message: err.Error()}}
+	}
+	rawJSON, err := json.Marshal(raw)
+	if err != nil {
+		return []*Error{{Line: document.Line, Key: "(root)", Message: err.Error()}}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(rawJSON))
+	decoder.UseNumber()
+	var instance interface{}
+	if err := decoder.Decode(&instance); err != nil {
+		return []*Error{{Line: document.Line, Key: "(root)", Message: err.Error()}}
+	}
+
+	err = schema.Validate(instance)
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return nil
+	}
+
+	var errs []*Error
+	seen := make(map[string]bool)
+	for _, leaf := range leafErrors(validationErr) {
+		tokens := pointerTokens(leaf.InstanceLocation)
+		var found []*Error
+
+		// Point unknown keys at the key itself rather than its parent
+		if match := unknownProperties.FindStringSubmatch(leaf.Message); match != nil {
+			for _, name := range quotedName.FindAllStringSubmatch(match[1], -1) {
+				keyTokens := append(append([]string{}, tokens...), name[1])
+				found = append(found, &Error{Line: lineOf(document, keyTokens), Key: keyPath(keyTokens), Message: "unknown key"})
+			}
+		} else {
+			found = append(found, &Error{Line: lineOf(document, tokens), Key: keyPath(tokens), Message: leaf.Message})
+		}
+
+		for _, e := range found {
+			if !seen[e.Key+e.Message] {
+				seen[e.Key+e.Message] = true
+				errs = append(errs, e)
+			}
+		}
+	}
+	return errs
+}
+
+// leafErrors returns the most specific validation errors
+func leafErrors(ve *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(ve.Causes) == 0 {
+		return []*jsonschema.ValidationError{ve}
+	}
+	var leaves []*jsonschema.ValidationError
+	for _, cause := range ve.Causes {
+		leaves = append(leaves, leafErrors(cause)...)
+	}
+	return leaves
+}
+
+// pointerTokens splits a JSON Pointer into its reference tokens
+func pointerTokens(pointer string) []string {
+	if pointer == "" || pointer == "/" {
+		return nil
+	}
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens
+}
+
+// keyPath renders tokens as a readable key such as targets[0].format
+func keyPath(tokens []string) string {
+	if len(tokens) == 0 {
+		return "(root)"
+	}
+	var b strings.Builder
+	for _, token := range tokens {
+		if _, err := strconv.Atoi(token); err == nil {
+			b.WriteString("[" + token + "]")
+		} else {
+			if b.Len() > 0 {
+				b.WriteString(".")
+			}
+			b.WriteString(token)
+		}
+	}
+	return b.String()
+}
+
+// lineOf returns the line in the document that the tokens refer to, or that of the closest parent
+func lineOf(document *yaml.Node, tokens []string) int {
+	node := document
+	line := document.Line
+	for _, token := range tokens {
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == token {
+					line = node.Content[i].Line
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(token); err == nil && i < len(node.Content) {
+				next = node.Content[i]
+				line = next.Line
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return line
+}
+
+// withFile sets the file on each error and joins them
+func withFile(path string, errs []*Error) error {
+	joined := make([]error, 0, len(errs))
+	for _, e := range errs {
+		e.File = path
+		joined = append(joined, e)
+	}
+	return errors.Join(joined...)
+}
@@ -0,0 +1,235 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a configuration file to a temporary directory
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `
+nxrm:
+    url: https://nexus.example.com
+    username: admin
+targets:
+    - format: npm
+      repositories: [npm-proxy]
+      packages:
+          policies: [Security-Critical]
+          include: ['^bson@']
+policyMapping:
+    Security-Critical:
+        patterns: ['^Critical']
+output:
+    color: false
+purgeCache: true
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Nxrm.URL != "https://nexus.example.com" || cfg.Nxrm.Username != "admin" {
+		t.Errorf("Nxrm = %+v", cfg.Nxrm)
+	}
+	if len(cfg.Targets) != 1 || cfg.Targets[0].Format != "npm" {
+		t.Fatalf("Targets = %+v", cfg.Targets)
+	}
+	filter := cfg.Targets[0].PackageFilter()
+	if len(filter.Policies) != 1 || len(filter.Include) != 1 || !filter.Include[0].MatchString("bson@1.0.9") {
+		t.Errorf("PackageFilter() = %+v", filter)
+	}
+	if aliases := cfg.GetPolicyMapping()["Security-Critical"]; len(aliases.Patterns) != 1 {
+		t.Errorf("GetPolicyMapping() = %+v", cfg.GetPolicyMapping())
+	}
+	if cfg.Output.UseColor() {
+		t.Error("UseColor() = true, want false")
+	}
+	if !cfg.PurgeCache {
+		t.Error("PurgeCache = false, want true")
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	t.Setenv("NXFW_TEST_URL", "https://nexus.example.com")
+	t.Setenv("NXFW_TEST_COLOR", "false")
+	t.Setenv("NXFW_TEST_PASSWORD", "123456")
+	t.Setenv("NXFW_TEST_PURGE", "true")
+
+	tests := []struct {
+		name    string
+		content string
+		check   func(t *testing.T, cfg *RunConfig)
+	}{
+		{
+			name:    "string",
+			content: "nxrm:\n    url: ${NXFW_TEST_URL}\ntargets:\n    - format: npm\n      repositories: [npm-proxy]\n",
+			check: func(t *testing.T, cfg *RunConfig) {
+				if cfg.Nxrm.URL != "https://nexus.example.com" {
+					t.Errorf("Nxrm.URL = %q", cfg.Nxrm.URL)
+				}
+			},
+		},
+		{
+			name:    "boolean",
+			content: "nxrm:\n    url: https://nexus.example.com\ntargets:\n    - format: npm\n      repositories: [npm-proxy]\noutput:\n    color: ${NXFW_TEST_COLOR}\n",
+			check: func(t *testing.T, cfg *RunConfig) {
+				if cfg.Output.UseColor() {
+					t.Error("UseColor() = true, want false")
+				}
+			},
+		},
+		{
+			name:    "quoted number stays a string",
+			content: "nxrm:\n    url: https://nexus.example.com\n    password: '${NXFW_TEST_PASSWORD}'\ntargets:\n    - format: npm\n      repositories: [npm-proxy]\n",
+			check: func(t *testing.T, cfg *RunConfig) {
+				if cfg.Nxrm.Password != "123456" {
+					t.Errorf("Nxrm.Password = %q", cfg.Nxrm.Password)
+				}
+			},
+		},
+		{
+			name:    "unquoted number stays a string",
+			content: "nxrm:\n    url: https://nexus.example.com\n    password: ${NXFW_TEST_PASSWORD}\ntargets:\n    - format: npm\n      repositories: [npm-proxy]\n",
+			check: func(t *testing.T, cfg *RunConfig) {
+				if cfg.Nxrm.Password != "123456" {
+					t.Errorf("Nxrm.Password = %q", cfg.Nxrm.Password)
+				}
+			},
+		},
+		{
+			name:    "unquoted boolean-like value stays a string",
+			content: "nxrm:\n    url: https://nexus.example.com\n    password: ${NXFW_TEST_COLOR}\ntargets:\n    - format: npm\n      repositories: [npm-proxy]\n",
+			check: func(t *testing.T, cfg *RunConfig) {
+				if cfg.Nxrm.Password != "false" {
+					t.Errorf("Nxrm.Password = %q", cfg.Nxrm.Password)
+				}
+			},
+		},
+		{
+			name:    "top-level boolean",
+			content: "nxrm:\n    url: https://nexus.example.com\ntargets:\n    - format: npm\n      repositories: [npm-proxy]\npurgeCache: ${NXFW_TEST_PURGE}\n",
+			check: func(t *testing.T, cfg *RunConfig) {
+				if !cfg.PurgeCache {
+					t.Error("PurgeCache = false, want true")
+				}
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg, err := Load(writeConfig(t, test.content))
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			test.check(t, cfg)
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "empty",
+			content: "",
+			want:    []string{"(root): configuration is empty"},
+		},
+		{
+			name:    "missing environment variable",
+			content: "nxrm:\n    url: ${NXFW_TEST_UNSET}\ntargets:\n    - format: npm\n      repositories: [npm-proxy]\n",
+			want:    []string{":2: nxrm.url: environment variable NXFW_TEST_UNSET is not set"},
+		},
+		{
+			name:    "unknown key",
+			content: "nxrm:\n    url: https://nexus.example.com\n    token: abc\ntargets:\n    - format: npm\n      repositories: [npm-proxy]\n",
+			want:    []string{":3: nxrm.token: unknown key"},
+		},
+		{
+			name:    "wrong type",
+			content: "nxrm:\n    url: https://nexus.example.com\ntargets:\n    - format: npm\n      repositories: [npm-proxy]\noutput:\n    color: sometimes\n",
+			want:    []string{":7: output.color:"},
+		},
+		{
+			name:    "unknown format",
+			content: "nxrm:\n    url: https://nexus.example.com\ntargets:\n    - format: rubygems\n      repositories: [gems-proxy]\n",
+			want:    []string{":4: targets[0].format:"},
+		},
+		{
+			name:    "missing targets",
+			content: "nxrm:\n    url: https://nexus.example.com\n",
+			want:    []string{"targets"},
+		},
+		{
+			name:    "invalid pattern",
+			content: "nxrm:\n    url: https://nexus.example.com\ntargets:\n    - format: npm\n      repositories: [npm-proxy]\n      packages:\n          exclude: ['(']\n",
+			want:    []string{":7: targets[0].packages.exclude[0]: invalid regular expression"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("NXFW_TEST_UNSET", "")
+			_ = os.Unsetenv("NXFW_TEST_UNSET")
+
+			_, err := Load(writeConfig(t, test.content))
+			if err == nil {
+				t.Fatal("Load() error = nil")
+			}
+			for _, want := range test.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Load() error = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSchemaType(t *testing.T) {
+	tests := []struct {
+		tokens []string
+		want   string
+	}{
+		{[]string{"output", "color"}, "boolean"},
+		{[]string{"purgeCache"}, "boolean"},
+		{[]string{"discoverPolicies"}, "boolean"},
+		{[]string{"nxrm", "password"}, "string"},
+		{[]string{"targets", "0", "format"}, "string"},
+		{[]string{"unknown"}, ""},
+		{[]string{"nxrm", "unknown"}, ""},
+		{nil, "object"},
+	}
+	for _, test := range tests {
+		if got := schemaType(test.tokens); got != test.want {
+			t.Errorf("schemaType(%v) = %q, want %q", test.tokens, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/cli"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/report"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/util"
+)
+
+// metricsServer serves the results of the most recent successful run at /metrics in daemon mode, and whether
+// the most recent run failed
+type metricsServer struct {
+	mu             sync.RWMutex
+	lastSuccessful *report.Report
+	lastRunFailed  bool
+	runs           int
+}
+
+// startMetricsServer listens on the given address and serves metrics in the background
+func startMetricsServer(listen string) (*metricsServer, error) {
+	server := &metricsServer{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", server.serveMetrics)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = fmt.Fprintf(w, "%s %s - metrics at /metrics\n", toolName, version)
+	})
+
+	// Listen before serving in the background, so that a bad address is reported straight away
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serve metrics on %s: %w", listen, err)
+	}
+	go func() {
+		err := http.Serve(listener, mux)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			cli.PrintCliln(fmt.Sprintf("Warning: Stopped serving metrics on %s: %v", listen, err), util.ColorYellow)
+		}
+	}()
+	cli.PrintCliln(fmt.Sprintf("✓ Serving metrics at http://%s/metrics", listener.Addr()), util.ColorGreen)
+
+	return server, nil
+}
+
+// update serves the metrics of a successful run
+func (s *metricsServer) update(runReport *report.Report) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastSuccessful = runReport
+	s.lastRunFailed = false
+	s.runs++
+}
+
+// failed records that a run failed - the results of the last successful run continue to be served
+func (s *metricsServer) failed() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastRunFailed = true
+	s.runs++
+}
+
+func (s *metricsServer) serveMetrics(w http.ResponseWriter, _ *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.runs == 0 {
+		http.Error(w, "The first run has not completed yet", http.StatusServiceUnavailable)
+		return
+	}
+	var buf bytes.Buffer
+	if err := (report.OpenMetricsReporter{}).WriteDaemon(&buf, s.lastSuccessful, s.lastRunFailed); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", report.OpenMetricsContentType)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// waitForNextRun reports when the next run in daemon mode will start, and waits until then
+func waitForNextRun(interval time.Duration) {
+	cli.PrintCliln(fmt.Sprintf("\nNext run at %s", time.Now().Add(interval).Format("2006-01-02 15:04:05")), util.ColorYellow)
+	time.Sleep(interval)
+}
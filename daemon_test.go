@@ -0,0 +1,58 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/report"
+)
+
+func TestMetricsServer(t *testing.T) {
+	server := &metricsServer{}
+	scrape := func() (int, string) {
+		recorder := httptest.NewRecorder()
+		server.serveMetrics(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		return recorder.Code, recorder.Body.String()
+	}
+
+	if code, _ := scrape(); code != http.StatusServiceUnavailable {
+		t.Errorf("before the first run, status = %d, want %d", code, http.StatusServiceUnavailable)
+	}
+
+	server.failed()
+	code, body := scrape()
+	if code != http.StatusOK || !strings.Contains(body, "nxfw_policy_test_last_run_failed 1\n") || strings.Contains(body, "run_timestamp_seconds") {
+		t.Errorf("after a failed first run, status = %d, body = %s", code, body)
+	}
+
+	server.update(&report.Report{Runs: []report.Run{{Format: "npm", Repository: "npm-proxy"}}})
+	if _, body := scrape(); !strings.Contains(body, "nxfw_policy_test_last_run_failed 0\n") || !strings.Contains(body, `repository="npm-proxy"`) {
+		t.Errorf("after a successful run, body = %s", body)
+	}
+
+	server.failed()
+	if _, body := scrape(); !strings.Contains(body, "nxfw_policy_test_last_run_failed 1\n") || !strings.Contains(body, `repository="npm-proxy"`) {
+		t.Errorf("after a failed run, body = %s, want the last successful results", body)
+	}
+	if _, body := scrape(); !strings.HasSuffix(body, "# EOF\n") {
+		t.Errorf("body = %s, want it to end with # EOF", body)
+	}
+}
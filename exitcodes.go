@@ -0,0 +1,99 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/cli"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/report"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/util"
+)
+
+// Exit codes
+const (
+	exitOK             = 0 // All expectations met
+	exitRunError       = 1 // The run could not complete, or results were inconclusive
+	exitPolicyGap      = 2 // A package expected to be quarantined was downloadable
+	exitPolicyMismatch = 3 // A package was quarantined, but not by the expected policy
+)
+
+// parseFailOn parses a comma separated list of outcomes that should cause a non-zero exit code
+func parseFailOn(value string) (map[report.Outcome]bool, error) {
+	failOn := make(map[report.Outcome]bool)
+	for _, part := range strings.Split(value, ",") {
+		outcome := report.Outcome(strings.TrimSpace(part))
+		switch outcome {
+		case report.OutcomeGap, report.OutcomeMismatch, report.OutcomeInconclusive:
+			failOn[outcome] = true
+		case "":
+		default:
+			return nil, fmt.Errorf("invalid --fail-on value '%s' - expected gap, mismatch or inconclusive", outcome)
+		}
+	}
+	return failOn, nil
+}
+
+// determineExitCode reports the outcome of the run and returns the exit code for it - policy gaps take
+// precedence over mismatched policies, which take precedence over inconclusive results
+func determineExitCode(targets []runTarget, failOn map[report.Outcome]bool) int {
+	counts := make(map[report.Outcome]int)
+	unevaluated := 0
+	for _, target := range targets {
+		for _, result := range target.results {
+			outcome := report.Classify(result, target.format)
+			counts[outcome]++
+			if outcome == report.OutcomeInconclusive && result.Cached != nil {
+				unevaluated++
+			}
+		}
+	}
+
+	cli.PrintCliln("", util.ColorReset)
+	exitCode := exitOK
+	switch {
+	case failOn[report.OutcomeGap] && counts[report.OutcomeGap] > 0:
+		exitCode = exitPolicyGap
+	case failOn[report.OutcomeMismatch] && counts[report.OutcomeMismatch] > 0:
+		exitCode = exitPolicyMismatch
+	case failOn[report.OutcomeInconclusive] && counts[report.OutcomeInconclusive] > 0:
+		exitCode = exitRunError
+	}
+
+	if counts[report.OutcomeGap] > 0 {
+		cli.PrintCliln(fmt.Sprintf("✗ %d package(s) expected to be quarantined were downloadable", counts[report.OutcomeGap]), util.ColorRed)
+	}
+	if counts[report.OutcomeMismatch] > 0 {
+		cli.PrintCliln(fmt.Sprintf("✗ %d package(s) were quarantined unexpectedly, or not by the expected policy", counts[report.OutcomeMismatch]), util.ColorRed)
+	}
+	if unevaluated > 0 {
+		cli.PrintCliln(fmt.Sprintf("? %d package(s) were not evaluated by Firewall because they were already cached - use --purge-cache to test them", unevaluated), util.ColorYellow)
+	}
+	if other := counts[report.OutcomeInconclusive] - unevaluated; other > 0 {
+		cli.PrintCliln(fmt.Sprintf("? %d package(s) could not be checked conclusively", other), util.ColorYellow)
+	}
+	switch {
+	case exitCode != exitOK:
+		cli.PrintCliln(fmt.Sprintf("Exit code %d", exitCode), util.ColorRed)
+	case counts[report.OutcomeGap]+counts[report.OutcomeMismatch]+counts[report.OutcomeInconclusive] == 0:
+		cli.PrintCliln(fmt.Sprintf("✓ All expectations met (exit code %d)", exitCode), util.ColorGreen)
+	default:
+		cli.PrintCliln(fmt.Sprintf("✓ No outcomes selected by --fail-on (exit code %d)", exitCode), util.ColorGreen)
+	}
+
+	return exitCode
+}
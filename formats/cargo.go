@@ -29,6 +29,10 @@ func (c CargoFormat) GetDisplayName() string {
 	return "Cargo"
 }
 
+func (c CargoFormat) GetUpstreamURL() string {
+	return "https://index.crates.io/"
+}
+
 func (c CargoFormat) GetPackages() []Package {
 	// Placeholder - packages will be provided later
 	return []Package{
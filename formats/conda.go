@@ -32,6 +32,10 @@ func (c CondaFormat) GetDisplayName() string {
 	return "Conda"
 }
 
+func (c CondaFormat) GetUpstreamURL() string {
+	return "https://repo.anaconda.com/pkgs/"
+}
+
 func (c CondaFormat) GetPackages() []Package {
 	return []Package{
 		// Security
@@ -31,6 +31,10 @@ func (p CranFormat) GetDisplayName() string {
 	return "CRAN(R)"
 }
 
+func (p CranFormat) GetUpstreamURL() string {
+	return "https://cran.r-project.org/"
+}
+
 func (p CranFormat) GetPackages() []Package {
 	return []Package{
 		{Name: "readxl", Version: "0.1.0", PolicyName: SecurityHigh, Extension: "tar.gz"},
@@ -31,6 +31,10 @@ func (m DockerFormat) GetDisplayName() string {
 	return "Docker"
 }
 
+func (m DockerFormat) GetUpstreamURL() string {
+	return "https://registry-1.docker.io"
+}
+
 func (m DockerFormat) GetPackages() []Package {
 	return []Package{
 		// Security
@@ -39,8 +43,9 @@ func (m DockerFormat) GetPackages() []Package {
 		{Name: "sonatypecommunity/docker-policy-demo", Version: "Security-Medium", PolicyName: SecurityMedium, Extension: ""},
 		{Name: "sonatypecommunity/docker-policy-demo", Version: "Security-Low", PolicyName: SecurityLow, Extension: ""},
 		{Name: "sonatypecommunity/docker-policy-demo", Version: "Security-Malicious", PolicyName: SecurityMalicious, Extension: ""},
-		{Name: "sonatypecommunity/docker-policy-demo", Version: "Integrity-Suspicious", PolicyName: IntegrityRating, Extension: ""},
-		{Name: "sonatypecommunity/docker-policy-demo", Version: "Integrity-Pending", PolicyName: IntegrityRating, Extension: ""},
+		// Built from test-data/Dockerfile.IntegritySuspicious and test-data/Dockerfile.IntegrityPending
+		{Name: "sonatypecommunity/docker-policy-demo", Version: "Integrity-Suspicious", PolicyName: IntegrityRating, Extension: "", Integrity: IntegritySuspicious},
+		{Name: "sonatypecommunity/docker-policy-demo", Version: "Integrity-Pending", PolicyName: IntegrityRating, Extension: "", Integrity: IntegrityPending},
 
 		// Legal
 	}
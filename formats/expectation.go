@@ -0,0 +1,93 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package formats
+
+// ExpectedAction is what Sonatype Repository Firewall is expected to do with a package
+type ExpectedAction string
+
+const (
+	// ExpectQuarantine - the package should be quarantined by its policy
+	ExpectQuarantine ExpectedAction = "quarantine"
+	// ExpectAllow - the package should be downloadable
+	ExpectAllow ExpectedAction = "allow"
+	// ExpectWarnOnly - the policy is violated but only warns at the Proxy stage, so the package should be downloadable
+	ExpectWarnOnly ExpectedAction = "warn-only"
+	// ExpectNoPolicy - no Sonatype IQ Server policy takes the place of the Reference Policy, so the package
+	// cannot be tested. Only set by policy discovery
+	ExpectNoPolicy ExpectedAction = "no-policy"
+)
+
+// AllExpectedActions lists every ExpectedAction that may be set by hand
+var AllExpectedActions = []ExpectedAction{ExpectQuarantine, ExpectAllow, ExpectWarnOnly}
+
+// BlocksDownload returns true if the package is expected to be quarantined
+func (a ExpectedAction) BlocksDownload() bool {
+	return a == ExpectQuarantine
+}
+
+// DefaultExpectedAction returns what the Reference Policy Set does with packages violating this policy
+func (p PolicyName) DefaultExpectedAction() ExpectedAction {
+	if p == None {
+		return ExpectAllow
+	}
+	return ExpectQuarantine
+}
+
+// GetExpectedAction returns the expected action for the package - set explicitly, or the default for its policy
+func (p Package) GetExpectedAction() ExpectedAction {
+	if p.Expected != "" {
+		return p.Expected
+	}
+	return p.PolicyName.DefaultExpectedAction()
+}
+
+// PackageExpectation overrides the expected action for matching packages - empty fields match any value
+type PackageExpectation struct {
+	Format  string
+	Name    string
+	Version string
+	Policy  PolicyName
+	Action  ExpectedAction
+}
+
+// Expectations overrides the expected action of packages, by policy or for individual packages. Package
+// expectations take precedence over policy expectations
+type Expectations struct {
+	Policies map[PolicyName]ExpectedAction
+	Packages []PackageExpectation
+}
+
+// IsEmpty returns true if no expectations are overridden
+func (e Expectations) IsEmpty() bool {
+	return len(e.Policies) == 0 && len(e.Packages) == 0
+}
+
+// Apply sets the expected action of the package
+func (e Expectations) Apply(format PackageFormat, pkg Package) Package {
+	if action, ok := e.Policies[pkg.PolicyName]; ok {
+		pkg.Expected = action
+	}
+	for _, expectation := range e.Packages {
+		if (expectation.Format == "" || expectation.Format == format.GetName()) &&
+			(expectation.Name == "" || expectation.Name == pkg.Name) &&
+			(expectation.Version == "" || expectation.Version == pkg.Version) &&
+			(expectation.Policy == "" || expectation.Policy == pkg.PolicyName) {
+			pkg.Expected = expectation.Action
+		}
+	}
+	return pkg
+}
@@ -0,0 +1,83 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package formats
+
+import "regexp"
+
+// PackageFilter restricts which packages of a PackageFormat are checked
+type PackageFilter struct {
+	Policies []PolicyName
+	Include  []*regexp.Regexp
+	Exclude  []*regexp.Regexp
+}
+
+// Matches returns true if the package passes the filter - Include and Exclude are
+// matched against the name returned by FormatPackageName
+func (f PackageFilter) Matches(format PackageFormat, pkg Package) bool {
+	if len(f.Policies) > 0 {
+		found := false
+		for _, p := range f.Policies {
+			if p == pkg.PolicyName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	packageName := format.FormatPackageName(pkg)
+	if len(f.Include) > 0 {
+		found := false
+		for _, re := range f.Include {
+			if re.MatchString(packageName) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for _, re := range f.Exclude {
+		if re.MatchString(packageName) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FilteredFormat wraps a PackageFormat so that only packages matching Filter are returned, with
+// Expectations applied
+type FilteredFormat struct {
+	PackageFormat
+	Filter       PackageFilter
+	Expectations Expectations
+}
+
+func (f FilteredFormat) GetPackages() []Package {
+	var packages []Package
+	for _, pkg := range f.PackageFormat.GetPackages() {
+		if f.Filter.Matches(f.PackageFormat, pkg) {
+			packages = append(packages, f.Expectations.Apply(f.PackageFormat, pkg))
+		}
+	}
+	return packages
+}
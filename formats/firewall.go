@@ -0,0 +1,43 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package formats
+
+// FirewallStatus is whether Sonatype Repository Firewall audits and quarantines a Proxy Repository
+type FirewallStatus struct {
+	Known             bool
+	AuditEnabled      bool
+	QuarantineEnabled bool
+}
+
+// QuarantineDisabled returns true if the repository is known not to quarantine components, so a test run
+// against it cannot show whether policies are enforced
+func (s FirewallStatus) QuarantineDisabled() bool {
+	return s.Known && !s.QuarantineEnabled
+}
+
+func (s FirewallStatus) String() string {
+	switch {
+	case !s.Known:
+		return "Firewall status unknown"
+	case s.QuarantineEnabled:
+		return "audit + quarantine"
+	case s.AuditEnabled:
+		return "audit only, quarantine disabled"
+	default:
+		return "Firewall disabled"
+	}
+}
@@ -31,6 +31,10 @@ func (n GolangFormat) GetDisplayName() string {
 	return "Golang"
 }
 
+func (n GolangFormat) GetUpstreamURL() string {
+	return "https://proxy.golang.org/"
+}
+
 func (n GolangFormat) GetPackages() []Package {
 	return []Package{
 		// Security
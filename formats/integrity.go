@@ -0,0 +1,85 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package formats
+
+import "time"
+
+// IntegrityState is the state of a component's Sonatype integrity rating, which the Integrity-Rating Reference
+// Policy quarantines on
+type IntegrityState string
+
+const (
+	// IntegrityPending - the component has not been rated yet, so is quarantined until it is
+	IntegrityPending IntegrityState = "pending"
+	// IntegritySuspicious - the component was rated suspicious, so stays quarantined
+	IntegritySuspicious IntegrityState = "suspicious"
+	// IntegrityQuarantined - the component is still quarantined, but no longer pending, without a suspicious rating
+	IntegrityQuarantined IntegrityState = "quarantined"
+	// IntegrityReleased - the component is no longer quarantined
+	IntegrityReleased IntegrityState = "released"
+)
+
+// IntegrityEvent is the integrity state of a component when it was observed
+type IntegrityEvent struct {
+	At    time.Time
+	State IntegrityState
+}
+
+// IntegrityTimeline records how the integrity state of a pending test package changed while it was tracked
+// (--track-pending)
+type IntegrityTimeline struct {
+	QuarantinedAt  *time.Time
+	Events         []IntegrityEvent // Only changes of state are recorded
+	LastObservedAt time.Time
+	Resolved       bool // Released or confirmed quarantined before tracking timed out
+}
+
+// Observe records the state of the component, if it has changed
+func (t *IntegrityTimeline) Observe(at time.Time, state IntegrityState) {
+	t.LastObservedAt = at
+	if len(t.Events) > 0 && t.Events[len(t.Events)-1].State == state {
+		return
+	}
+	t.Events = append(t.Events, IntegrityEvent{At: at, State: state})
+	if state != IntegrityPending {
+		t.Resolved = true
+	}
+}
+
+// State returns the last observed state of the component
+func (t *IntegrityTimeline) State() IntegrityState {
+	if len(t.Events) == 0 {
+		return IntegrityPending
+	}
+	return t.Events[len(t.Events)-1].State
+}
+
+// BlockedFor returns how long the component was quarantined while pending - until it was resolved or, if it
+// never was, until it was last observed
+func (t *IntegrityTimeline) BlockedFor() time.Duration {
+	if len(t.Events) == 0 {
+		return 0
+	}
+	started := t.Events[0].At
+	if t.QuarantinedAt != nil && t.QuarantinedAt.Before(started) {
+		started = *t.QuarantinedAt
+	}
+	if t.Resolved {
+		return t.Events[len(t.Events)-1].At.Sub(started)
+	}
+	return t.LastObservedAt.Sub(started)
+}
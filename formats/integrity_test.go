@@ -0,0 +1,126 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package formats
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestIntegrityTimeline(t *testing.T) {
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	quarantinedAt := start.Add(-time.Minute)
+	tests := []struct {
+		name          string
+		quarantinedAt *time.Time
+		observed      []IntegrityState // Observed a minute apart, from start
+		wantStates    []IntegrityState
+		wantState     IntegrityState
+		wantResolved  bool
+		wantBlocked   time.Duration
+	}{
+		{
+			name:      "not observed",
+			wantState: IntegrityPending,
+		},
+		{
+			name:        "still pending",
+			observed:    []IntegrityState{IntegrityPending, IntegrityPending, IntegrityPending},
+			wantStates:  []IntegrityState{IntegrityPending},
+			wantState:   IntegrityPending,
+			wantBlocked: 2 * time.Minute,
+		},
+		{
+			name:         "released",
+			observed:     []IntegrityState{IntegrityPending, IntegrityPending, IntegrityReleased},
+			wantStates:   []IntegrityState{IntegrityPending, IntegrityReleased},
+			wantState:    IntegrityReleased,
+			wantResolved: true,
+			wantBlocked:  2 * time.Minute,
+		},
+		{
+			name:          "released since quarantine",
+			quarantinedAt: &quarantinedAt,
+			observed:      []IntegrityState{IntegrityPending, IntegrityReleased},
+			wantStates:    []IntegrityState{IntegrityPending, IntegrityReleased},
+			wantState:     IntegrityReleased,
+			wantResolved:  true,
+			wantBlocked:   2 * time.Minute,
+		},
+		{
+			name:         "confirmed suspicious",
+			observed:     []IntegrityState{IntegrityPending, IntegritySuspicious},
+			wantStates:   []IntegrityState{IntegrityPending, IntegritySuspicious},
+			wantState:    IntegritySuspicious,
+			wantResolved: true,
+			wantBlocked:  time.Minute,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			timeline := &IntegrityTimeline{QuarantinedAt: test.quarantinedAt}
+			for i, state := range test.observed {
+				timeline.Observe(start.Add(time.Duration(i)*time.Minute), state)
+			}
+
+			var states []IntegrityState
+			for _, event := range timeline.Events {
+				states = append(states, event.State)
+			}
+			if !slices.Equal(states, test.wantStates) {
+				t.Errorf("Events = %v, want %v", states, test.wantStates)
+			}
+			if got := timeline.State(); got != test.wantState {
+				t.Errorf("State() = %s, want %s", got, test.wantState)
+			}
+			if timeline.Resolved != test.wantResolved {
+				t.Errorf("Resolved = %v, want %v", timeline.Resolved, test.wantResolved)
+			}
+			if got := timeline.BlockedFor(); got != test.wantBlocked {
+				t.Errorf("BlockedFor() = %s, want %s", got, test.wantBlocked)
+			}
+		})
+	}
+}
+
+func TestPolicyDemoIntegrityStates(t *testing.T) {
+	tests := []struct {
+		format PackageFormat
+		want   map[string]IntegrityState
+	}{
+		{NPMFormat{}, map[string]IntegrityState{"2.2.0": IntegritySuspicious, "2.3.0": IntegrityPending}},
+		{MavenFormat{}, map[string]IntegrityState{"1.2.0": IntegritySuspicious, "1.3.0": IntegrityPending}},
+		{PyPIFormat{}, map[string]IntegrityState{"1.2.0": IntegritySuspicious, "1.3.0": IntegrityPending}},
+		{DockerFormat{}, map[string]IntegrityState{"Integrity-Suspicious": IntegritySuspicious, "Integrity-Pending": IntegrityPending}},
+		{HuggingFaceFormat{}, map[string]IntegrityState{}},
+	}
+	for _, test := range tests {
+		t.Run(test.format.GetName(), func(t *testing.T) {
+			got := make(map[string]IntegrityState)
+			for _, pkg := range test.format.GetPackages() {
+				if pkg.PolicyName == IntegrityRating && pkg.Integrity != "" {
+					got[pkg.Version] = pkg.Integrity
+				}
+			}
+			if !maps.Equal(got, test.want) {
+				t.Errorf("integrity states = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package formats
+
+import "regexp"
+
+// PolicyAliases are the names of the customer's Sonatype IQ Server policies that take the place of a
+// Reference Policy
+type PolicyAliases struct {
+	Names    []string
+	Patterns []*regexp.Regexp
+}
+
+// PolicyMapping maps Reference Policies to the names of the customer's Sonatype IQ Server policies. A
+// Reference Policy always matches a policy of the same name
+type PolicyMapping map[PolicyName]PolicyAliases
+
+// Matches returns true if the Sonatype IQ Server policy named actual takes the place of the Reference Policy
+func (m PolicyMapping) Matches(reference PolicyName, actual string) bool {
+	if actual == string(reference) {
+		return true
+	}
+
+	aliases, ok := m[reference]
+	if !ok {
+		return false
+	}
+	for _, name := range aliases.Names {
+		if name == actual {
+			return true
+		}
+	}
+	for _, pattern := range aliases.Patterns {
+		if pattern.MatchString(actual) {
+			return true
+		}
+	}
+	return false
+}
+
+// Describe returns the names and patterns mapped to each Reference Policy, for reports
+func (m PolicyMapping) Describe() map[string][]string {
+	if len(m) == 0 {
+		return nil
+	}
+	described := make(map[string][]string, len(m))
+	for reference, aliases := range m {
+		names := append([]string{}, aliases.Names...)
+		for _, pattern := range aliases.Patterns {
+			names = append(names, "/"+pattern.String()+"/")
+		}
+		described[string(reference)] = names
+	}
+	return described
+}
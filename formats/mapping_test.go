@@ -0,0 +1,65 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package formats
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestPolicyMappingMatches(t *testing.T) {
+	mapping := PolicyMapping{
+		SecurityCritical: {Names: []string{"Critical Vulnerability"}},
+		SecurityHigh:     {Patterns: []*regexp.Regexp{regexp.MustCompile(`^Security-High(-.+)?$`)}},
+	}
+	tests := []struct {
+		name      string
+		reference PolicyName
+		actual    string
+		want      bool
+	}{
+		{"reference name", SecurityCritical, "Security-Critical", true},
+		{"reference name without mapping", SecurityMedium, "Security-Medium", true},
+		{"alias name", SecurityCritical, "Critical Vulnerability", true},
+		{"alias name of other policy", SecurityHigh, "Critical Vulnerability", false},
+		{"alias name is exact", SecurityCritical, "Critical Vulnerability (npm)", false},
+		{"pattern", SecurityHigh, "Security-High-npm", true},
+		{"pattern anchored", SecurityHigh, "Legacy-Security-High", false},
+		{"unmapped", SecurityMedium, "Medium Vulnerability", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := mapping.Matches(test.reference, test.actual); got != test.want {
+				t.Errorf("Matches(%s, %q) = %v, want %v", test.reference, test.actual, got, test.want)
+			}
+		})
+	}
+}
+
+func TestPolicyMappingDescribe(t *testing.T) {
+	if described := (PolicyMapping{}).Describe(); described != nil {
+		t.Errorf("Describe() = %v, want nil", described)
+	}
+
+	mapping := PolicyMapping{
+		SecurityHigh: {Names: []string{"High"}, Patterns: []*regexp.Regexp{regexp.MustCompile(`^High-`)}},
+	}
+	if got := mapping.Describe()[string(SecurityHigh)]; !slices.Equal(got, []string{"High", "/^High-/"}) {
+		t.Errorf("Describe() = %v", got)
+	}
+}
@@ -32,6 +32,10 @@ func (m MavenFormat) GetDisplayName() string {
 	return "Maven"
 }
 
+func (m MavenFormat) GetUpstreamURL() string {
+	return "https://repo1.maven.org/maven2/"
+}
+
 func (m MavenFormat) GetPackages() []Package {
 	return []Package{
 		// Security
@@ -40,8 +44,11 @@ func (m MavenFormat) GetPackages() []Package {
 		{Name: "ant/ant", Version: "1.6.5", PolicyName: SecurityMedium, Extension: "jar"},
 		{Name: "org.springframework/spring-context", Version: "6.2.3", PolicyName: SecurityLow, Extension: "jar"},
 		{Name: "org.sonatype/maven-policy-demo", Version: "1.1.0", PolicyName: SecurityMalicious, Extension: "jar"},
-		{Name: "org.sonatype/maven-policy-demo", Version: "1.2.0", PolicyName: IntegrityRating, Extension: "jar"},
-		{Name: "org.sonatype/maven-policy-demo", Version: "1.3.0", PolicyName: IntegrityRating, Extension: "jar"},
+		// The policy-demo test packages are rated Suspicious at x.2.0 and Pending at x.3.0 - see
+		// test-data/Dockerfile.IntegritySuspicious and test-data/Dockerfile.IntegrityPending, built from
+		// maven-policy-demo 1.2.0 and 1.3.0
+		{Name: "org.sonatype/maven-policy-demo", Version: "1.2.0", PolicyName: IntegrityRating, Extension: "jar", Integrity: IntegritySuspicious},
+		{Name: "org.sonatype/maven-policy-demo", Version: "1.3.0", PolicyName: IntegrityRating, Extension: "jar", Integrity: IntegrityPending},
 
 		// Legal
 		{Name: "com.itextpdf/bouncy-castle-connector", Version: "9.3.0", PolicyName: LicenseBanned, Extension: "jar"},
@@ -33,6 +33,10 @@ func (n NPMFormat) GetDisplayName() string {
 	return "NPM"
 }
 
+func (n NPMFormat) GetUpstreamURL() string {
+	return "https://registry.npmjs.org/"
+}
+
 func (n NPMFormat) GetPackages() []Package {
 	return []Package{
 		// Security
@@ -40,8 +44,11 @@ func (n NPMFormat) GetPackages() []Package {
 		{Name: "braces", Version: "1.8.5", PolicyName: SecurityHigh, Extension: "tgz"},
 		{Name: "cookie", Version: "0.3.1", PolicyName: SecurityMedium, Extension: "tgz"},
 		{Name: "react-dom", Version: "18.3.1", PolicyName: SecurityLow, Extension: "tgz"},
-		{Name: "@sonatype/policy-demo", Version: "2.3.0", PolicyName: IntegrityRating, Extension: "tgz"},
-		{Name: "@sonatype/policy-demo", Version: "2.2.0", PolicyName: IntegrityRating, Extension: "tgz"},
+		// The policy-demo test packages are rated Suspicious at x.2.0 and Pending at x.3.0 - see
+		// test-data/Dockerfile.IntegritySuspicious and test-data/Dockerfile.IntegrityPending, built from
+		// maven-policy-demo 1.2.0 and 1.3.0
+		{Name: "@sonatype/policy-demo", Version: "2.2.0", PolicyName: IntegrityRating, Extension: "tgz", Integrity: IntegritySuspicious},
+		{Name: "@sonatype/policy-demo", Version: "2.3.0", PolicyName: IntegrityRating, Extension: "tgz", Integrity: IntegrityPending},
 		{Name: "@sonatype/policy-demo", Version: "2.1.0", PolicyName: SecurityMalicious, Extension: "tgz"},
 
 		// Legal
@@ -32,6 +32,10 @@ func (n NuGetFormat) GetDisplayName() string {
 	return "NuGet"
 }
 
+func (n NuGetFormat) GetUpstreamURL() string {
+	return "https://api.nuget.org/v3/index.json"
+}
+
 func (n NuGetFormat) GetPackages() []Package {
 	return []Package{
 		// Security
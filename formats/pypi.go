@@ -32,6 +32,10 @@ func (p PyPIFormat) GetDisplayName() string {
 	return "PyPI"
 }
 
+func (p PyPIFormat) GetUpstreamURL() string {
+	return "https://pypi.org/"
+}
+
 func (p PyPIFormat) GetPackages() []Package {
 	return []Package{
 		// Security
@@ -40,8 +44,11 @@ func (p PyPIFormat) GetPackages() []Package {
 		{Name: "Click", Version: "7.0", PolicyName: SecurityMedium, Extension: "whl", Qualifier: "py2.py3-none-any"},
 		{Name: "requests-toolbelt", Version: "1.0.0", PolicyName: SecurityLow, Extension: "tar.gz", Qualifier: ""},
 		{Name: "python-policy-demo", Version: "1.1.0", PolicyName: SecurityMalicious, Extension: "tar.gz", Qualifier: ""},
-		{Name: "python-policy-demo", Version: "1.2.0", PolicyName: IntegrityRating, Extension: "tar.gz", Qualifier: ""},
-		{Name: "python-policy-demo", Version: "1.3.0", PolicyName: IntegrityRating, Extension: "tar.gz", Qualifier: ""},
+		// The policy-demo test packages are rated Suspicious at x.2.0 and Pending at x.3.0 - see
+		// test-data/Dockerfile.IntegritySuspicious and test-data/Dockerfile.IntegrityPending, built from
+		// maven-policy-demo 1.2.0 and 1.3.0
+		{Name: "python-policy-demo", Version: "1.2.0", PolicyName: IntegrityRating, Extension: "tar.gz", Qualifier: "", Integrity: IntegritySuspicious},
+		{Name: "python-policy-demo", Version: "1.3.0", PolicyName: IntegrityRating, Extension: "tar.gz", Qualifier: "", Integrity: IntegrityPending},
 
 		// Legal
 		{Name: "nltk", Version: "3.9.2", PolicyName: LicenseBanned, Extension: "whl", Qualifier: "py3-none-any"},
@@ -16,7 +16,11 @@
 
 package formats
 
-import "github.com/sonatype-nexus-community/nxfw-policy-tester/util"
+import (
+	"time"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/util"
+)
 
 // PolicyName represents the security classification of a package
 type PolicyName string
@@ -39,15 +43,82 @@ const (
 	None                     PolicyName = "None"
 )
 
-func (p PolicyName) GetSecurityColor() string {
+// AllPolicyNames lists every Reference Policy this tool has test data for
+var AllPolicyNames = []PolicyName{
+	SecurityCritical,
+	SecurityHigh,
+	SecurityMedium,
+	SecurityLow,
+	IntegrityRating,
+	SecurityMalicious,
+	LicenseBanned,
+	LicenseNone,
+	LicenseCopyLeft,
+	LicenseThreatNotAssigned,
+	LicenseAIML,
+	LicenseCommercial,
+	LicenseNonStandard,
+	LicenseWeakCopyleft,
+	None,
+}
+
+// ReferenceThreatLevels holds the threat level (0 - 10) of each policy in the Reference Policy Set. Policies
+// missing here have no confirmed threat level, so are not compared by audit-policies
+var ReferenceThreatLevels = map[PolicyName]int32{
+	SecurityMalicious: 10,
+	SecurityCritical:  10,
+	SecurityHigh:      9,
+	SecurityMedium:    7,
+	LicenseBanned:     10,
+}
+
+// ReferenceConstraints holds the names of the constraints of each policy in the Reference Policy Set. A policy
+// has a reference constraint if one of its constraint names contains it, ignoring case and punctuation.
+// Policies missing here have no confirmed constraint names, so their constraints are not compared by
+// audit-policies
+var ReferenceConstraints = map[PolicyName][]string{
+	SecurityCritical: {"Critical risk CVSS score"},
+	SecurityHigh:     {"High risk CVSS score"},
+	SecurityMedium:   {"Medium risk CVSS score"},
+	SecurityLow:      {"Low risk CVSS score"},
+	IntegrityRating:  {"Suspicious", "Pending"},
+}
+
+// ThreatClass groups policies by the severity of the threat they represent
+type ThreatClass string
+
+const (
+	ThreatCritical ThreatClass = "critical"
+	ThreatSevere   ThreatClass = "severe"
+	ThreatModerate ThreatClass = "moderate"
+	ThreatLow      ThreatClass = "low"
+	ThreatNone     ThreatClass = "none"
+)
+
+func (p PolicyName) GetThreatClass() ThreatClass {
 	switch p {
 	case LicenseBanned, LicenseNone, LicenseCopyLeft, IntegrityRating, SecurityCritical, SecurityMalicious:
-		return util.ColorRed
+		return ThreatCritical
 	case LicenseAIML, LicenseCommercial, LicenseThreatNotAssigned, SecurityHigh:
-		return util.ColorMagenta
+		return ThreatSevere
 	case LicenseNonStandard, LicenseWeakCopyleft, SecurityMedium:
-		return util.ColorYellow
+		return ThreatModerate
 	case None:
+		return ThreatNone
+	default:
+		return ThreatLow
+	}
+}
+
+func (p PolicyName) GetSecurityColor() string {
+	switch p.GetThreatClass() {
+	case ThreatCritical:
+		return util.ColorRed
+	case ThreatSevere:
+		return util.ColorMagenta
+	case ThreatModerate:
+		return util.ColorYellow
+	case ThreatNone:
 		return util.ColorGreen
 	default:
 		return util.ColorReset
@@ -60,25 +131,81 @@ type Package struct {
 	Version    string
 	PolicyName PolicyName
 	Extension  string
-	Qualifier  string // For PyPI wheel qualifiers like py2.py3-none-any
+	Qualifier  string         // For PyPI wheel qualifiers like py2.py3-none-any
+	Expected   ExpectedAction // Overrides the default for PolicyName - see GetExpectedAction
+	Integrity  IntegrityState // For Integrity-Rating test data, whether the package is pending or suspicious
 }
 
 // PackageFormat represents a package format handler
 type PackageFormat interface {
 	GetName() string
 	GetDisplayName() string
+	GetUpstreamURL() string // Public registry proxied by a provisioned repository - see --provision
 	GetPackages() []Package
 	ConstructURL(nexusURL, repoName string, pkg Package) string
 	FormatPackageName(pkg Package) string
 }
 
+// CachedAsset describes a test package asset found in the repository before it was requested
+type CachedAsset struct {
+	Path           string
+	BlobCreated    *time.Time
+	LastDownloaded *time.Time
+}
+
+// QuarantineRelease records a test package this run caused to be quarantined, and then released
+type QuarantineRelease struct {
+	QuarantineId  string
+	QuarantinedAt *time.Time
+	ReleasedAt    time.Time
+}
+
+// ResultStatus summarises a CheckResult
+type ResultStatus string
+
+const (
+	StatusAvailable              ResultStatus = "AVAILABLE"
+	StatusCached                 ResultStatus = "CACHED" // Downloadable, but already cached - Firewall does not re-evaluate it
+	StatusQuarantined            ResultStatus = "QUARANTINED"
+	StatusQuarantinedOtherPolicy ResultStatus = "QUARANTINED_OTHER_POLICY"
+	StatusFailed                 ResultStatus = "FAILED"
+	StatusUnknown                ResultStatus = "UNKNOWN"
+)
+
 // CheckResult represents the result of checking a package
 type CheckResult struct {
 	Package                       Package
+	URL                           string
 	Available                     bool
 	Failed                        bool
 	HTTPCode                      int
 	Quarantined                   bool
 	QuarantinedByPolicies         []string
 	QuarantinedWithExpectedPolicy bool
+	MatchedPolicy                 string // Sonatype IQ Server policy matching the expected Reference Policy
+	QuarantinePageURL             string
+	QuarantineHash                string             // Hash of the quarantined component in Sonatype IQ Server
+	QuarantineDisabled            bool               // Quarantine is not enabled for the repository - see FirewallStatus
+	Cached                        *CachedAsset       // The asset was already in the repository before it was requested
+	Released                      *QuarantineRelease // Released from quarantine once the run completed (--release-after)
+	IntegrityTimeline             *IntegrityTimeline // How a pending package was resolved (--track-pending)
+	Duration                      time.Duration
+}
+
+// Status returns the summary status of this result
+func (r CheckResult) Status() ResultStatus {
+	switch {
+	case r.Available && r.Cached != nil:
+		return StatusCached
+	case r.Available:
+		return StatusAvailable
+	case r.Quarantined && r.QuarantinedWithExpectedPolicy:
+		return StatusQuarantined
+	case r.Quarantined:
+		return StatusQuarantinedOtherPolicy
+	case r.Failed:
+		return StatusFailed
+	default:
+		return StatusUnknown
+	}
 }
@@ -6,4 +6,11 @@ toolchain go1.24.8
 
 require github.com/sonatype-nexus-community/nexus-repo-api-client-go/v3 v3.84.1
 
-require github.com/sonatype-nexus-community/nexus-iq-api-client-go v0.196.4
+require (
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
+	github.com/sonatype-nexus-community/nexus-iq-api-client-go v0.196.4
+	go.etcd.io/bbolt v1.4.3
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.29.0 // indirect
@@ -0,0 +1,330 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/cli"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/history"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/report"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/util"
+)
+
+// historyFileUsage describes the --history-file flag shared by run and history
+const historyFileUsage = "History database (defaults to " + history.FileName + " in the user configuration directory)"
+
+// historyCommand lists, shows and charts the trend of previous runs
+func historyCommand(args []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		args = append([]string{"list"}, args...)
+	}
+
+	switch args[0] {
+	case "list":
+		historyListCommand(args[1:])
+	case "show":
+		historyShowCommand(args[1:])
+	case "trend":
+		historyTrendCommand(args[1:])
+	default:
+		cli.PrintCliln(fmt.Sprintf("Error: Unknown history command '%s' - expected list, show or trend", args[0]), util.ColorRed)
+		os.Exit(exitRunError)
+	}
+}
+
+// openHistory opens the history database, defaulting to the user's config directory
+func openHistory(path string) (*history.Store, error) {
+	if path == "" {
+		var err error
+		if path, err = history.DefaultPath(toolName); err != nil {
+			return nil, fmt.Errorf("cannot locate history: %w", err)
+		}
+	}
+	return history.Open(path)
+}
+
+// mustOpenHistory opens the history database for the history commands, exiting on failure
+func mustOpenHistory(path string) *history.Store {
+	store, err := openHistory(path)
+	if err != nil {
+		cli.PrintCliln(fmt.Sprintf("Error: %v", err), util.ColorRed)
+		os.Exit(exitRunError)
+	}
+	return store
+}
+
+// saveHistory records a run in the history database - failure is reported but does not fail the run
+func saveHistory(path string, runReport *report.Report) {
+	store, err := openHistory(path)
+	if err != nil {
+		cli.PrintCliln(fmt.Sprintf("Warning: Failed to save run to history: %v", err), util.ColorYellow)
+		return
+	}
+	defer func() { _ = store.Close() }()
+
+	id, err := store.Save(runReport)
+	if err != nil {
+		cli.PrintCliln(fmt.Sprintf("Warning: Failed to save run to history: %v", err), util.ColorYellow)
+		return
+	}
+	cli.PrintCliln(fmt.Sprintf("✓ Saved run %s to history", id), util.ColorGreen)
+}
+
+// historyListCommand lists previous runs, most recent first
+func historyListCommand(args []string) {
+	flags := newFlagSet("history list")
+	historyFile := flags.String("history-file", "", historyFileUsage)
+	nexusURL := flags.String("nxrm-url", "", "Only list runs against this Sonatype Nexus Repository")
+	limit := flags.Int("limit", 20, "Maximum number of runs to list (0 for all)")
+	parseFlags(flags, args)
+
+	store := mustOpenHistory(*historyFile)
+	defer func() { _ = store.Close() }()
+
+	summaries, err := store.List(*nexusURL)
+	if err != nil {
+		cli.PrintCliln(fmt.Sprintf("Error: %v", err), util.ColorRed)
+		os.Exit(exitRunError)
+	}
+	if len(summaries) == 0 {
+		cli.PrintCliln("No runs in history", util.ColorYellow)
+		return
+	}
+	if *limit > 0 && len(summaries) > *limit {
+		summaries = summaries[:*limit]
+	}
+
+	fmt.Printf("%-20s %-19s %-35s %6s %6s %6s  %s\n", "ID", "Started", "Sonatype Nexus Repository", "PASS", "FAIL", "INCONC", "Tested")
+	for _, summary := range summaries {
+		fmt.Printf(
+			"%-20s %-19s %-35s %6d %6d %6d  %s\n",
+			summary.ID,
+			summary.StartedAt.Local().Format("2006-01-02 15:04:05"),
+			summary.NxrmURL,
+			summary.Verdicts[report.VerdictPass],
+			summary.Verdicts[report.VerdictFail],
+			summary.Verdicts[report.VerdictInconclusive],
+			strings.Join(summary.Targets, ", "),
+		)
+	}
+}
+
+// historyShowCommand displays the results of a previous run
+func historyShowCommand(args []string) {
+	flags := newFlagSet("history show")
+	historyFile := flags.String("history-file", "", historyFileUsage)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: nxfw-policy-tester history show [flags] <run id|latest>\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	parseFlags(flags, args)
+
+	id := "latest"
+	if flags.NArg() > 0 {
+		id = flags.Arg(0)
+	}
+
+	store := mustOpenHistory(*historyFile)
+	defer func() { _ = store.Close() }()
+
+	runReport, err := store.Get(id)
+	if errors.Is(err, history.ErrRunNotFound) {
+		cli.PrintCliln(fmt.Sprintf("Error: Run '%s' is not in history - see 'history list'", id), util.ColorRed)
+		os.Exit(exitRunError)
+	} else if err != nil {
+		cli.PrintCliln(fmt.Sprintf("Error: %v", err), util.ColorRed)
+		os.Exit(exitRunError)
+	}
+
+	cli.PrintCliln(fmt.Sprintf("Sonatype Nexus Repository: %s", runReport.NxrmURL), util.ColorReset)
+	cli.PrintCliln(fmt.Sprintf("Sonatype IQ Server:        %s", runReport.IqURL), util.ColorReset)
+	cli.PrintCliln(fmt.Sprintf("Started:                   %s", runReport.StartedAt.Local().Format("2006-01-02 15:04:05")), util.ColorReset)
+	cli.PrintCliln(fmt.Sprintf("Tool:                      %s %s (%s)", runReport.Tool.Name, runReport.Tool.Version, runReport.Tool.Commit), util.ColorReset)
+
+	for _, run := range runReport.Runs {
+		displayStoredRun(run)
+	}
+}
+
+// displayStoredRun displays the results of a run from history in the same way as displayResults
+func displayStoredRun(run report.Run) {
+	verdicts := make(map[report.Verdict]int)
+	for _, result := range run.Results {
+		verdicts[result.Verdict]++
+	}
+
+	cli.PrintCliln(fmt.Sprintf("\n>> %s - %s", run.FormatDisplayName, run.Repository), util.ColorYellow)
+	cli.PrintCliln("============================= Test Summary =============================", util.ColorYellow)
+	cli.PrintCliln(fmt.Sprintf("PASS:                 %02d", verdicts[report.VerdictPass]), util.ColorGreen)
+	cli.PrintCliln(fmt.Sprintf("FAIL:                 %02d", verdicts[report.VerdictFail]), util.ColorRed)
+	cli.PrintCliln(fmt.Sprintf("INCONCLUSIVE:         %02d", verdicts[report.VerdictInconclusive]), util.ColorYellow)
+
+	cli.PrintCliln("\n------------------------------- Details --------------------------------", util.ColorYellow)
+	for _, result := range run.Results {
+		packageName := result.Package.DisplayName
+		if len(packageName) > 35 {
+			packageName = packageName[len(packageName)-35:]
+		}
+		cli.PrintCliln(
+			fmt.Sprintf(
+				"%s%-12s%s %20s: %35s - %s%s%s",
+				verdictColor(result.Verdict),
+				result.Verdict,
+				result.ExpectedPolicy.GetSecurityColor(),
+				result.ExpectedPolicy,
+				packageName,
+				statusColor(result.Status),
+				result.Status,
+				util.ColorReset,
+			),
+			result.ExpectedPolicy.GetSecurityColor(),
+		)
+	}
+}
+
+// statusColor returns the colour used for a result status in the terminal
+func statusColor(status formats.ResultStatus) string {
+	switch status {
+	case formats.StatusAvailable:
+		return util.ColorGreen
+	case formats.StatusCached:
+		return util.ColorYellow
+	case formats.StatusQuarantined:
+		return util.ColorCyan
+	default:
+		return util.ColorRed
+	}
+}
+
+// statusSymbol returns a single character summarising a result status, for the trend chart
+func statusSymbol(status formats.ResultStatus) string {
+	switch status {
+	case formats.StatusAvailable:
+		return "A"
+	case formats.StatusCached:
+		return "C"
+	case formats.StatusQuarantined:
+		return "Q"
+	case formats.StatusQuarantinedOtherPolicy:
+		return "q"
+	case formats.StatusFailed:
+		return "F"
+	case "":
+		return "."
+	default:
+		return "?"
+	}
+}
+
+// historyTrendCommand charts how the status of each package has changed across previous runs
+func historyTrendCommand(args []string) {
+	flags := newFlagSet("history trend")
+	historyFile := flags.String("history-file", "", historyFileUsage)
+	nexusURL := flags.String("nxrm-url", "", "Sonatype Nexus Repository the runs were against (may be omitted if history only holds one)")
+	formatName := flags.String("format", "", "Package format")
+	repoName := flags.String("repository", "", "Name of the Proxy Repository")
+	limit := flags.Int("limit", 10, "Number of most recent runs to chart")
+	parseFlags(flags, args)
+
+	if *formatName == "" || *repoName == "" {
+		cli.PrintCliln("Error: --format and --repository are required", util.ColorRed)
+		os.Exit(exitRunError)
+	}
+	format := resolveFormat(*formatName)
+
+	store := mustOpenHistory(*historyFile)
+	defer func() { _ = store.Close() }()
+
+	if *nexusURL == "" {
+		instances, err := store.Instances()
+		if err != nil {
+			cli.PrintCliln(fmt.Sprintf("Error: %v", err), util.ColorRed)
+			os.Exit(exitRunError)
+		}
+		if len(instances) != 1 {
+			cli.PrintCliln(fmt.Sprintf("Error: --nxrm-url is required - history holds runs against: %s", strings.Join(instances, ", ")), util.ColorRed)
+			os.Exit(exitRunError)
+		}
+		*nexusURL = instances[0]
+	}
+
+	entries, err := store.Entries(*nexusURL, format.GetName(), *repoName)
+	if err != nil {
+		cli.PrintCliln(fmt.Sprintf("Error: %v", err), util.ColorRed)
+		os.Exit(exitRunError)
+	}
+	if len(entries) == 0 {
+		cli.PrintCliln(fmt.Sprintf("No runs in history for %s - %s on %s", format.GetDisplayName(), *repoName, *nexusURL), util.ColorYellow)
+		return
+	}
+	if *limit > 0 && len(entries) > *limit {
+		entries = entries[len(entries)-*limit:]
+	}
+
+	// Rows are the packages of every charted run, in the order first seen
+	type trendRow struct {
+		policy   formats.PolicyName
+		name     string
+		statuses []formats.ResultStatus
+	}
+	var rows []*trendRow
+	rowIndex := make(map[string]*trendRow)
+	for i, entry := range entries {
+		for _, result := range entry.Run.Results {
+			key := string(result.ExpectedPolicy) + "|" + result.Package.DisplayName
+			row, ok := rowIndex[key]
+			if !ok {
+				row = &trendRow{policy: result.ExpectedPolicy, name: result.Package.DisplayName, statuses: make([]formats.ResultStatus, len(entries))}
+				rowIndex[key] = row
+				rows = append(rows, row)
+			}
+			row.statuses[i] = result.Status
+		}
+	}
+
+	cli.PrintCliln(fmt.Sprintf("%s - %s on %s", format.GetDisplayName(), *repoName, *nexusURL), util.ColorYellow)
+	cli.PrintCliln("A = available, C = cached, Q = quarantined, q = quarantined by another policy, F = failed, ? = unknown, . = not tested\n", util.ColorReset)
+	for i, entry := range entries {
+		cli.PrintCliln(fmt.Sprintf("%58s%s+ %s", "", strings.Repeat("|", i), entry.ID), util.ColorReset)
+	}
+
+	for _, row := range rows {
+		var chart strings.Builder
+		agedOut := ""
+		for i, status := range row.statuses {
+			chart.WriteString(statusColor(status) + statusSymbol(status) + util.ColorReset)
+			if i > 0 && status == formats.StatusAvailable &&
+				(row.statuses[i-1] == formats.StatusQuarantined || row.statuses[i-1] == formats.StatusQuarantinedOtherPolicy) {
+				agedOut = fmt.Sprintf("  ⚠ no longer quarantined since %s", entries[i].ID)
+			}
+		}
+
+		name := row.name
+		if len(name) > 35 {
+			name = name[len(name)-35:]
+		}
+		cli.PrintCliln(
+			fmt.Sprintf("%20s: %35s %s%s%s", row.policy, name, chart.String(), util.ColorRed, agedOut),
+			row.policy.GetSecurityColor(),
+		)
+	}
+}
@@ -0,0 +1,261 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/report"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// FileName is the name of the history database within the configuration directory
+	FileName = "history.db"
+
+	// idLayout is used to derive run identifiers from the time a run started - they sort chronologically
+	idLayout = "20060102-150405.000"
+)
+
+var (
+	// runsBucket holds every run's complete report, keyed by run identifier
+	runsBucket = []byte("runs")
+	// indexBucket holds a bucket per Sonatype Nexus Repository instance, holding a bucket per format and
+	// repository, holding the identifiers of the runs that tested it
+	indexBucket = []byte("index")
+
+	// ErrRunNotFound is returned when a run identifier is not in the store
+	ErrRunNotFound = errors.New("run not found")
+)
+
+// Store is the local history of runs
+type Store struct {
+	db *bolt.DB
+}
+
+// Summary describes a stored run without its results
+type Summary struct {
+	ID         string
+	NxrmURL    string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Targets    []string
+	Verdicts   map[report.Verdict]int
+}
+
+// Entry is the result of testing a single format and repository in a stored run
+type Entry struct {
+	ID      string
+	NxrmURL string
+	Run     report.Run
+}
+
+// DefaultPath returns the location of the history database under the user's configuration directory
+func DefaultPath(toolName string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, toolName, FileName), nil
+}
+
+// Open opens (creating if needed) the history database at path
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 2 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the history database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Save records a run, returning its identifier
+func (s *Store) Save(r *report.Report) (string, error) {
+	id := r.StartedAt.UTC().Format(idLayout)
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		runs, err := tx.CreateBucketIfNotExists(runsBucket)
+		if err != nil {
+			return err
+		}
+		if err := runs.Put([]byte(id), data); err != nil {
+			return err
+		}
+
+		index, err := tx.CreateBucketIfNotExists(indexBucket)
+		if err != nil {
+			return err
+		}
+		instance, err := index.CreateBucketIfNotExists([]byte(instanceKey(r.NxrmURL)))
+		if err != nil {
+			return err
+		}
+		for _, run := range r.Runs {
+			target, err := instance.CreateBucketIfNotExists([]byte(targetKey(run.Format, run.Repository)))
+			if err != nil {
+				return err
+			}
+			if err := target.Put([]byte(id), nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return id, err
+}
+
+// List returns a summary of every stored run, most recent first, optionally restricted to a Sonatype Nexus
+// Repository instance
+func (s *Store) List(nxrmURL string) ([]Summary, error) {
+	var summaries []Summary
+	err := s.db.View(func(tx *bolt.Tx) error {
+		runs := tx.Bucket(runsBucket)
+		if runs == nil {
+			return nil
+		}
+		c := runs.Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			r, err := report.Parse(v)
+			if err != nil {
+				return fmt.Errorf("run %s is corrupt: %w", k, err)
+			}
+			if nxrmURL != "" && instanceKey(r.NxrmURL) != instanceKey(nxrmURL) {
+				continue
+			}
+			summary := Summary{
+				ID:         string(k),
+				NxrmURL:    r.NxrmURL,
+				StartedAt:  r.StartedAt,
+				FinishedAt: r.FinishedAt,
+				Verdicts:   make(map[report.Verdict]int),
+			}
+			for _, run := range r.Runs {
+				summary.Targets = append(summary.Targets, targetKey(run.Format, run.Repository))
+				for _, result := range run.Results {
+					summary.Verdicts[result.Verdict]++
+				}
+			}
+			summaries = append(summaries, summary)
+		}
+		return nil
+	})
+	return summaries, err
+}
+
+// Get returns a stored run. The identifier "latest" returns the most recent run
+func (s *Store) Get(id string) (*report.Report, error) {
+	var r *report.Report
+	err := s.db.View(func(tx *bolt.Tx) error {
+		runs := tx.Bucket(runsBucket)
+		if runs == nil {
+			return ErrRunNotFound
+		}
+		var data []byte
+		if id == "latest" {
+			_, data = runs.Cursor().Last()
+		} else {
+			data = runs.Get([]byte(id))
+		}
+		if data == nil {
+			return ErrRunNotFound
+		}
+		var err error
+		r, err = report.Parse(data)
+		return err
+	})
+	return r, err
+}
+
+// Entries returns the results of every stored run that tested the format and repository on the Sonatype
+// Nexus Repository instance, oldest first
+func (s *Store) Entries(nxrmURL, format, repository string) ([]Entry, error) {
+	var entries []Entry
+	err := s.db.View(func(tx *bolt.Tx) error {
+		runs := tx.Bucket(runsBucket)
+		index := tx.Bucket(indexBucket)
+		if runs == nil || index == nil {
+			return nil
+		}
+		instance := index.Bucket([]byte(instanceKey(nxrmURL)))
+		if instance == nil {
+			return nil
+		}
+		target := instance.Bucket([]byte(targetKey(format, repository)))
+		if target == nil {
+			return nil
+		}
+		return target.ForEach(func(k, _ []byte) error {
+			data := runs.Get(k)
+			if data == nil {
+				return nil
+			}
+			r, err := report.Parse(data)
+			if err != nil {
+				return fmt.Errorf("run %s is corrupt: %w", k, err)
+			}
+			for _, run := range r.Runs {
+				if run.Format == format && run.Repository == repository {
+					entries = append(entries, Entry{ID: string(k), NxrmURL: r.NxrmURL, Run: run})
+				}
+			}
+			return nil
+		})
+	})
+	return entries, err
+}
+
+// Instances returns every Sonatype Nexus Repository instance in the store
+func (s *Store) Instances() ([]string, error) {
+	var instances []string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		index := tx.Bucket(indexBucket)
+		if index == nil {
+			return nil
+		}
+		return index.ForEachBucket(func(k []byte) error {
+			instances = append(instances, string(k))
+			return nil
+		})
+	})
+	return instances, err
+}
+
+// instanceKey normalises a Sonatype Nexus Repository URL so that trailing slashes do not split its history
+func instanceKey(nxrmURL string) string {
+	return strings.TrimSuffix(strings.ToLower(nxrmURL), "/")
+}
+
+func targetKey(format, repository string) string {
+	return format + "/" + repository
+}
@@ -0,0 +1,257 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package history
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/report"
+)
+
+var start = time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+
+// reportAt returns a report of a run started the given number of hours after start, testing each target
+// (format/repository) with one package
+func reportAt(hours int, nxrmURL string, targets ...string) *report.Report {
+	r := &report.Report{
+		SchemaVersion: report.SchemaVersion,
+		NxrmURL:       nxrmURL,
+		StartedAt:     start.Add(time.Duration(hours) * time.Hour),
+		FinishedAt:    start.Add(time.Duration(hours)*time.Hour + time.Minute),
+	}
+	for _, target := range targets {
+		format, repository, _ := strings.Cut(target, "/")
+		r.Runs = append(r.Runs, report.Run{
+			Format:     format,
+			Repository: repository,
+			Results: []report.Result{{
+				Package:        report.PackageInfo{Name: "bson", Version: "1.0.9"},
+				ExpectedPolicy: formats.SecurityCritical,
+				Status:         formats.StatusQuarantined,
+			}},
+		})
+	}
+	return r
+}
+
+// openStore opens a store in a temporary directory, closing it when the test ends
+func openStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := Open(filepath.Join(t.TempDir(), "nested", FileName))
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// saveAll saves each report, failing the test on error
+func saveAll(t *testing.T, store *Store, reports ...*report.Report) []string {
+	t.Helper()
+	var ids []string
+	for _, r := range reports {
+		id, err := store.Save(r)
+		if err != nil {
+			t.Fatalf("Save() error: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestSave(t *testing.T) {
+	store := openStore(t)
+	ids := saveAll(t, store, reportAt(0, "https://nexus.example.com", "npm/npm-proxy"))
+	if ids[0] != "20260101-090000.000" {
+		t.Errorf("Save() id = %s, want 20260101-090000.000", ids[0])
+	}
+
+	r, err := store.Get(ids[0])
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	if r.NxrmURL != "https://nexus.example.com" || len(r.Runs) != 1 || r.Runs[0].Repository != "npm-proxy" {
+		t.Errorf("Get() = %+v, want the saved report", r)
+	}
+}
+
+func TestList(t *testing.T) {
+	store := openStore(t)
+	saveAll(t, store,
+		reportAt(0, "https://nexus.example.com", "npm/npm-proxy"),
+		reportAt(1, "https://other.example.com", "pypi/pypi-proxy"),
+		reportAt(2, "https://nexus.example.com/", "npm/npm-proxy", "maven2/maven-proxy"),
+	)
+
+	tests := []struct {
+		name    string
+		nxrmURL string
+		want    []string
+	}{
+		{"all instances", "", []string{"20260101-110000.000", "20260101-100000.000", "20260101-090000.000"}},
+		{"one instance", "https://nexus.example.com", []string{"20260101-110000.000", "20260101-090000.000"}},
+		{"instance by another spelling", "HTTPS://NEXUS.EXAMPLE.COM/", []string{"20260101-110000.000", "20260101-090000.000"}},
+		{"unknown instance", "https://unknown.example.com", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			summaries, err := store.List(test.nxrmURL)
+			if err != nil {
+				t.Fatalf("List() error: %v", err)
+			}
+			var ids []string
+			for _, summary := range summaries {
+				ids = append(ids, summary.ID)
+			}
+			if !slices.Equal(ids, test.want) {
+				t.Errorf("List() ids = %v, want %v", ids, test.want)
+			}
+		})
+	}
+
+	summaries, _ := store.List("")
+	latest := summaries[0]
+	if !slices.Equal(latest.Targets, []string{"npm/npm-proxy", "maven2/maven-proxy"}) {
+		t.Errorf("Targets = %v, want both tested", latest.Targets)
+	}
+	verdicts := 0
+	for _, count := range latest.Verdicts {
+		verdicts += count
+	}
+	if verdicts != 2 {
+		t.Errorf("Verdicts = %v, want one per result", latest.Verdicts)
+	}
+}
+
+func TestGet(t *testing.T) {
+	store := openStore(t)
+	if _, err := store.Get("latest"); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("Get(latest) on an empty store error = %v, want ErrRunNotFound", err)
+	}
+
+	saveAll(t, store,
+		reportAt(2, "https://nexus.example.com", "npm/npm-proxy"),
+		reportAt(0, "https://nexus.example.com", "npm/npm-proxy"),
+	)
+
+	tests := []struct {
+		name          string
+		id            string
+		wantStartedAt time.Time
+		wantErr       error
+	}{
+		{"by id", "20260101-090000.000", start, nil},
+		{"latest by start, not by saving", "latest", start.Add(2 * time.Hour), nil},
+		{"unknown id", "20250101-090000.000", time.Time{}, ErrRunNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := store.Get(test.id)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Get(%s) error = %v, want %v", test.id, err, test.wantErr)
+			}
+			if err == nil && !r.StartedAt.Equal(test.wantStartedAt) {
+				t.Errorf("Get(%s) started at %v, want %v", test.id, r.StartedAt, test.wantStartedAt)
+			}
+		})
+	}
+}
+
+func TestEntries(t *testing.T) {
+	store := openStore(t)
+	saveAll(t, store,
+		reportAt(1, "https://nexus.example.com/", "npm/npm-proxy", "pypi/pypi-proxy"),
+		reportAt(0, "https://nexus.example.com", "npm/npm-proxy"),
+		reportAt(2, "https://other.example.com", "npm/npm-proxy"),
+	)
+
+	tests := []struct {
+		name       string
+		nxrmURL    string
+		format     string
+		repository string
+		want       []string
+	}{
+		{"oldest first across spellings", "https://NEXUS.example.com", "npm", "npm-proxy", []string{"20260101-090000.000", "20260101-100000.000"}},
+		{"other repository", "https://nexus.example.com", "pypi", "pypi-proxy", []string{"20260101-100000.000"}},
+		{"other instance", "https://other.example.com", "npm", "npm-proxy", []string{"20260101-110000.000"}},
+		{"untested repository", "https://nexus.example.com", "maven2", "maven-proxy", nil},
+		{"unknown instance", "https://unknown.example.com", "npm", "npm-proxy", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entries, err := store.Entries(test.nxrmURL, test.format, test.repository)
+			if err != nil {
+				t.Fatalf("Entries() error: %v", err)
+			}
+			var ids []string
+			for _, entry := range entries {
+				ids = append(ids, entry.ID)
+				if entry.Run.Format != test.format || entry.Run.Repository != test.repository {
+					t.Errorf("entry %s is of %s/%s", entry.ID, entry.Run.Format, entry.Run.Repository)
+				}
+			}
+			if !slices.Equal(ids, test.want) {
+				t.Errorf("Entries() ids = %v, want %v", ids, test.want)
+			}
+		})
+	}
+}
+
+func TestInstances(t *testing.T) {
+	store := openStore(t)
+	if instances, err := store.Instances(); err != nil || len(instances) != 0 {
+		t.Errorf("Instances() on an empty store = %v, %v", instances, err)
+	}
+
+	saveAll(t, store,
+		reportAt(0, "https://nexus.example.com", "npm/npm-proxy"),
+		reportAt(1, "https://Nexus.example.com/", "npm/npm-proxy"),
+		reportAt(2, "https://other.example.com", "npm/npm-proxy"),
+	)
+	instances, err := store.Instances()
+	if err != nil {
+		t.Fatalf("Instances() error: %v", err)
+	}
+	if want := []string{"https://nexus.example.com", "https://other.example.com"}; !slices.Equal(instances, want) {
+		t.Errorf("Instances() = %v, want %v", instances, want)
+	}
+}
+
+func TestInstanceKey(t *testing.T) {
+	tests := []struct {
+		nxrmURL string
+		want    string
+	}{
+		{"https://nexus.example.com", "https://nexus.example.com"},
+		{"https://nexus.example.com/", "https://nexus.example.com"},
+		{"HTTPS://Nexus.Example.com/", "https://nexus.example.com"},
+		{"https://nexus.example.com/nexus/", "https://nexus.example.com/nexus"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := instanceKey(test.nxrmURL); got != test.want {
+			t.Errorf("instanceKey(%s) = %s, want %s", test.nxrmURL, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/cli"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/nxrm"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/util"
+)
+
+// listFormatsCommand lists the supported package formats
+func listFormatsCommand(args []string) {
+	flags := newFlagSet("list-formats")
+	parseFlags(flags, args)
+
+	for _, format := range allSupportedFormats {
+		fmt.Printf("%-15s %s\n", format.GetName(), format.GetDisplayName())
+	}
+}
+
+// listReposCommand lists the Proxy Repositories available for each (or the given) format
+func listReposCommand(args []string) {
+	flags := newFlagSet("list-repos")
+	nexusURL := flags.String("nxrm-url", "", "Sonatype Nexus Repository URL (e.g. https://nexus.example.com)")
+	formatName := flags.String("format", "", "Only list repositories for this package format")
+	parseFlags(flags, args)
+
+	creds := requireCredentials(credentials{}, false)
+	nxrmConnection := connectNxrm(resolveNexusURL(*nexusURL), creds)
+
+	selectedFormats := allSupportedFormats
+	if *formatName != "" {
+		selectedFormats = []formats.PackageFormat{resolveFormat(*formatName)}
+	}
+
+	// Listing still works if Firewall status cannot be read
+	statuses, _ := nxrmConnection.GetFirewallStatuses()
+
+	for _, format := range selectedFormats {
+		repos, err := nxrmConnection.GetProxyRepositories(format.GetName())
+		if err != nil {
+			cli.PrintCliln(fmt.Sprintf("Error: %v", err), util.ColorRed)
+			os.Exit(exitRunError)
+		}
+
+		for _, repo := range repos {
+			fmt.Printf("%-15s %-30s %s\n", format.GetName(), repo.GetName(), nxrm.FirewallStatusOf(statuses, repo.GetName()))
+		}
+	}
+}
+
+// listPackagesCommand lists the test packages for each (or the given) format
+func listPackagesCommand(args []string) {
+	flags := newFlagSet("list-packages")
+	formatName := flags.String("format", "", "Only list packages for this package format")
+	parseFlags(flags, args)
+
+	selectedFormats := allSupportedFormats
+	if *formatName != "" {
+		selectedFormats = []formats.PackageFormat{resolveFormat(*formatName)}
+	}
+
+	for _, format := range selectedFormats {
+		for _, pkg := range format.GetPackages() {
+			policy := string(pkg.PolicyName)
+			if pkg.Integrity != "" {
+				policy = fmt.Sprintf("%s (%s)", pkg.PolicyName, pkg.Integrity)
+			}
+			fmt.Printf("%-15s %-30s %s\n", format.GetName(), policy, format.FormatPackageName(pkg))
+		}
+	}
+}
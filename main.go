@@ -20,11 +20,11 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/sonatype-nexus-community/nxfw-policy-tester/cli"
 	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
-	"github.com/sonatype-nexus-community/nxfw-policy-tester/nxiq"
-	"github.com/sonatype-nexus-community/nxfw-policy-tester/nxrm"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/report"
 	"github.com/sonatype-nexus-community/nxfw-policy-tester/util"
 )
 
@@ -42,27 +42,38 @@ var (
 		formats.PyPIFormat{},
 	}
 	currentRuntime string = runtime.GOOS
+	toolName              = "nxfw-policy-tester"
 	commit                = "unknown"
 	version               = "dev"
 )
 
 // displaySummary displays the configuration summary
-func displaySummary(nexusURL, repoName string, format formats.PackageFormat) {
+func displaySummary(nexusURL, repoName string, format formats.PackageFormat, firewall formats.FirewallStatus) {
 	cli.PrintCliln("\n=== Configuration Summary ===\n", util.ColorYellow)
 	cli.PrintCliln("Nexus Repository URL: "+nexusURL, util.ColorReset)
 	cli.PrintCliln("Format: "+format.GetDisplayName(), util.ColorReset)
 	cli.PrintCliln("Repository: "+repoName, util.ColorReset)
+	if firewall.QuarantineDisabled() {
+		cli.PrintCliln(fmt.Sprintf("Repository Firewall: %s - results will be inconclusive", firewall), util.ColorYellow)
+	} else {
+		cli.PrintCliln(fmt.Sprintf("Repository Firewall: %s", firewall), util.ColorReset)
+	}
 	cli.PrintCliln("\nPackages to check:", util.ColorReset)
 
 	packages := format.GetPackages()
 	for _, pkg := range packages {
 		color := pkg.PolicyName.GetSecurityColor()
+		expected := ""
+		if action := pkg.GetExpectedAction(); action != pkg.PolicyName.DefaultExpectedAction() {
+			expected = fmt.Sprintf(" %s(expected %s)", util.ColorReset, action)
+		}
 		cli.PrintCliln(
 			fmt.Sprintf(
-				"  - %s %s[%s]",
+				"  - %s %s[%s]%s",
 				format.FormatPackageName(pkg),
 				color,
 				pkg.PolicyName,
+				expected,
 			),
 			util.ColorReset,
 		)
@@ -70,41 +81,62 @@ func displaySummary(nexusURL, repoName string, format formats.PackageFormat) {
 	fmt.Println()
 }
 
-// displayResults displays the check results summary
-func displayResults(results []formats.CheckResult, format formats.PackageFormat) {
-	availableCount := 0
-	failedCount := 0
-	quarantinedCount := 0
-
+// countVerdicts counts how many results passed, failed or were inconclusive against their expected action
+func countVerdicts(results []formats.CheckResult, format formats.PackageFormat) map[report.Verdict]int {
+	verdicts := make(map[report.Verdict]int)
 	for _, result := range results {
-		if result.Available {
-			availableCount++
-		} else if result.Quarantined {
-			quarantinedCount++
-		} else if result.Failed {
-			failedCount++
-		}
+		verdicts[report.Classify(result, format).Verdict()]++
+	}
+	return verdicts
+}
+
+// verdictColor returns the terminal colour for a verdict
+func verdictColor(verdict report.Verdict) string {
+	switch verdict {
+	case report.VerdictPass:
+		return util.ColorGreen
+	case report.VerdictFail:
+		return util.ColorRed
+	default:
+		return util.ColorYellow
 	}
+}
+
+// displayResults displays the check results summary
+func displayResults(results []formats.CheckResult, format formats.PackageFormat) {
+	verdicts := countVerdicts(results, format)
 
 	cli.PrintCliln("", util.ColorBlue)
 	cli.PrintCliln("============================= Test Summary =============================", util.ColorYellow)
-	cli.PrintCliln(fmt.Sprintf("Downloadable:         %02d", availableCount), util.ColorGreen)
-	cli.PrintCliln(fmt.Sprintf("Quarantined:          %02d", quarantinedCount), util.ColorCyan)
-	cli.PrintCliln(fmt.Sprintf("Failure:              %02d", failedCount), util.ColorRed)
+	cli.PrintCliln(fmt.Sprintf("PASS:                 %02d", verdicts[report.VerdictPass]), util.ColorGreen)
+	cli.PrintCliln(fmt.Sprintf("FAIL:                 %02d", verdicts[report.VerdictFail]), util.ColorRed)
+	cli.PrintCliln(fmt.Sprintf("INCONCLUSIVE:         %02d", verdicts[report.VerdictInconclusive]), util.ColorYellow)
 
 	cli.PrintCliln("\n------------------------------- Details --------------------------------", util.ColorYellow)
 	for _, result := range results {
 		color := result.Package.PolicyName.GetSecurityColor()
 		var status = "UNKNOWN"
-		if result.Available {
+		switch result.Status() {
+		case formats.StatusAvailable:
 			status = fmt.Sprintf("%sAVAILABLE%s", util.ColorGreen, util.ColorReset)
-		} else if result.Quarantined && result.QuarantinedWithExpectedPolicy {
+		case formats.StatusCached:
+			status = fmt.Sprintf("%sCACHED%s", util.ColorYellow, util.ColorReset)
+			if result.Cached.LastDownloaded != nil {
+				status = fmt.Sprintf(
+					"%sCACHED (last downloaded %s)%s",
+					util.ColorYellow, result.Cached.LastDownloaded.Format("2006-01-02"), util.ColorReset,
+				)
+			}
+		case formats.StatusQuarantined:
 			status = fmt.Sprintf("%sQUARANTINED%s", util.ColorCyan, util.ColorReset)
-		} else if result.Quarantined && !result.QuarantinedWithExpectedPolicy {
+			if result.MatchedPolicy != "" && result.MatchedPolicy != string(result.Package.PolicyName) {
+				status = fmt.Sprintf("%sQUARANTINED by %s%s", util.ColorCyan, result.MatchedPolicy, util.ColorReset)
+			}
+		case formats.StatusQuarantinedOtherPolicy:
 			status = fmt.Sprintf("%sQUARANTINED, but perhaps not by expected Reference Policy%s", util.ColorRed, util.ColorReset)
-		} else if result.Failed {
+		case formats.StatusFailed:
 			status = fmt.Sprintf("%sFAILED%s", util.ColorRed, util.ColorReset)
-		} else {
+		default:
 			println(fmt.Sprintf("Invalid Result? %v", result))
 		}
 
@@ -112,17 +144,45 @@ func displayResults(results []formats.CheckResult, format formats.PackageFormat)
 		if len(packageName) > 35 {
 			packageName = packageName[len(packageName)-35:]
 		}
+		verdict := report.Classify(result, format).Verdict()
+		expected := ""
+		if result.Package.GetExpectedAction() != result.Package.PolicyName.DefaultExpectedAction() {
+			expected = fmt.Sprintf(" (expected %s)", result.Package.GetExpectedAction())
+		}
 		cli.PrintCliln(
 			fmt.Sprintf(
-				"%20s: %35s - %15s",
+				"%s%-12s%s %20s: %35s - %15s%s",
+				verdictColor(verdict),
+				verdict,
+				color,
 				result.Package.PolicyName,
 				packageName,
 				status,
+				expected,
 			),
 			color,
 		)
 	}
 
+	cached := 0
+	for _, result := range results {
+		if result.Status() == formats.StatusCached {
+			cached++
+		}
+	}
+	if cached > 0 {
+		cli.PrintCliln(
+			fmt.Sprintf("\n⚠️ %d package(s) were already cached by the repository, so not evaluated by Firewall - use --purge-cache to test them.", cached),
+			util.ColorYellow,
+		)
+	}
+
+	displayIntegrityTimelines(results, format)
+
+	if len(results) > 0 && results[0].QuarantineDisabled {
+		cli.PrintCliln("\n⚠️ Quarantine is disabled for this repository, so every result is INCONCLUSIVE.", util.ColorYellow)
+	}
+
 	if format.GetName() == "docker" {
 		cli.PrintCliln("", util.ColorGreen)
 		cli.PrintCliln(
@@ -133,8 +193,84 @@ func displayResults(results []formats.CheckResult, format formats.PackageFormat)
 	}
 }
 
-func main() {
-	// Output Banner
+// displayIntegrityTimelines displays how each tracked pending package was resolved (--track-pending)
+func displayIntegrityTimelines(results []formats.CheckResult, format formats.PackageFormat) {
+	header := false
+	for _, result := range results {
+		timeline := result.IntegrityTimeline
+		if timeline == nil {
+			continue
+		}
+		if !header {
+			cli.PrintCliln("\n--------------------------- Pending Packages ---------------------------", util.ColorYellow)
+			header = true
+		}
+		color := util.ColorGreen
+		outcome := fmt.Sprintf("%s after %s", timeline.State(), timeline.BlockedFor().Round(time.Second))
+		if !timeline.Resolved {
+			color = util.ColorYellow
+			outcome = fmt.Sprintf("still pending after %s", timeline.BlockedFor().Round(time.Second))
+		}
+		cli.PrintCliln(fmt.Sprintf("%s: %s", format.FormatPackageName(result.Package), outcome), color)
+		if timeline.QuarantinedAt != nil {
+			cli.PrintCliln(fmt.Sprintf("  %s  quarantined", timeline.QuarantinedAt.Local().Format(time.DateTime)), util.ColorReset)
+		}
+		for _, event := range timeline.Events {
+			cli.PrintCliln(fmt.Sprintf("  %s  %s", event.At.Local().Format(time.DateTime), event.State), util.ColorReset)
+		}
+	}
+}
+
+// displayCombinedResults displays the results of every target, grouped by format and repository, followed
+// by an overall summary
+func displayCombinedResults(targets []runTarget) {
+	if len(targets) == 1 {
+		displayResults(targets[0].results, targets[0].format)
+		return
+	}
+
+	lastFormat := ""
+	for _, target := range targets {
+		if target.format.GetName() != lastFormat {
+			lastFormat = target.format.GetName()
+			cli.PrintCliln("", util.ColorReset)
+			cli.PrintCliln(strings.Repeat("#", 72), util.ColorYellow)
+			cli.PrintCliln(fmt.Sprintf("#  Format: %s", target.format.GetDisplayName()), util.ColorYellow)
+			cli.PrintCliln(strings.Repeat("#", 72), util.ColorYellow)
+		}
+		cli.PrintCliln(fmt.Sprintf("\n>> Repository: %s", target.repoName), util.ColorYellow)
+		displayResults(target.results, target.format)
+	}
+
+	cli.PrintCliln("", util.ColorReset)
+	cli.PrintCliln("=========================== Combined Summary ===========================", util.ColorYellow)
+	cli.PrintCliln(fmt.Sprintf("%-15s %-30s %8s %8s %12s", "Format", "Repository", "PASS", "FAIL", "INCONCLUSIVE"), util.ColorReset)
+	totals := make(map[report.Verdict]int)
+	for _, target := range targets {
+		verdicts := countVerdicts(target.results, target.format)
+		for verdict, count := range verdicts {
+			totals[verdict] += count
+		}
+		cli.PrintCliln(
+			fmt.Sprintf(
+				"%-15s %-30s %8d %8d %12d",
+				target.format.GetDisplayName(),
+				target.repoName,
+				verdicts[report.VerdictPass],
+				verdicts[report.VerdictFail],
+				verdicts[report.VerdictInconclusive],
+			),
+			util.ColorReset,
+		)
+	}
+	cli.PrintCliln(
+		fmt.Sprintf("%-46s %8d %8d %12d", "Total", totals[report.VerdictPass], totals[report.VerdictFail], totals[report.VerdictInconclusive]),
+		util.ColorYellow,
+	)
+}
+
+// printBanner outputs the banner along with runtime and version information
+func printBanner() {
 	println(strings.Repeat("⬢⬡", 42))
 	println("")
 	println("	███████╗ ██████╗ ███╗   ██╗ █████╗ ████████╗██╗   ██╗██████╗ ███████╗  ")
@@ -154,88 +290,24 @@ func main() {
 	println("")
 	println(strings.Repeat("⬢⬡", 42))
 	println("")
+}
 
-	// Get credentials from environment variables
-	nxrmUsername := os.Getenv("NXRM_USERNAME")
-	nxrmPassword := os.Getenv("NXRM_PASSWORD")
-	nxiqUsername := os.Getenv("NXIQ_USERNAME")
-	nxiqPassword := os.Getenv("NXIQ_PASSWORD")
-
-	if nxrmUsername == "" || nxrmPassword == "" {
-		fmt.Printf("%sError: NXRM_USERNAME and NXRM_PASSWORD environment variables must be set.%s\n", util.ColorRed, util.ColorReset)
-		fmt.Println("Example: export NXRM_USERNAME='your_username'")
-		fmt.Println("         export NXRM_PASSWORD='your_password'")
-		os.Exit(1)
-	}
-
-	if nxiqUsername == "" || nxiqPassword == "" {
-		fmt.Printf("%sError: NXIQ_PASSWORD and NXIQ_USERNAME environment variables must be set.%s\n", util.ColorRed, util.ColorReset)
-		fmt.Println("Example: export NXIQ_PASSWORD='your_username'")
-		fmt.Println("         export NXIQ_USERNAME='your_password'")
-		os.Exit(1)
-	}
-
-	// Get Nexus URL
-	fmt.Printf("\n%sEnter your Sonatype Nexus Repository URL:%s\n", util.ColorYellow, util.ColorReset)
-	fmt.Println("(Example: https://nexus.example.com)")
-	nexusURL := cli.ReadInput("")
-	nexusURL = strings.TrimSuffix(nexusURL, "/")
-
-	// Validate URL
-	if !strings.HasPrefix(nexusURL, "http://") && !strings.HasPrefix(nexusURL, "https://") {
-		fmt.Printf("%sError: Invalid URL format. URL must start with http:// or https://%s\n", util.ColorRed, util.ColorReset)
-		os.Exit(1)
-	}
-
-	// NXRM Connection
-	nxrmConnection, err := nxrm.NewNxrmConnection(nexusURL, nxrmUsername, nxrmPassword)
-	if err != nil {
-		os.Exit(1)
-	}
-
-	cli.PrintCliln("✓ Successfully authenticated with Sonatype Nexus Repository", util.ColorGreen)
-
-	// Get IQ URL
-	nxiqUrl, err := nxrmConnection.GetConnectedIqServer()
-	if err != nil {
-		os.Exit(1)
-	}
-
-	// NXIQ Connection
-	nxiqConnection, err := nxiq.NewNxiqConnection(nxiqUrl, nxiqUsername, nxiqPassword)
-	if err != nil {
-		os.Exit(1)
-	}
-
-	cli.PrintCliln(fmt.Sprintf("✓ Successfully authenticated with Sonatype IQ Server (%s)", nxiqUrl), util.ColorGreen)
-
-	// Select package format
-	format := cli.PromptSelectFormat(allSupportedFormats)
-
-	// Select Repository
-	repoName, err := nxrmConnection.SelectRepository(format.GetName())
-	if err != nil {
-		cli.PrintCliln(fmt.Sprintf("Error: %v", err), util.ColorRed)
-		os.Exit(1)
-	}
-
-	// Display summary
-	displaySummary(nexusURL, repoName, format)
+func main() {
+	args := os.Args[1:]
 
-	// Confirm
-	confirmation := cli.ReadInput("Proceed with checking packages? (y/n): ")
-	if !strings.HasPrefix(strings.ToLower(confirmation), "y") {
-		cli.PrintCliln("User cancelled.", util.ColorRed)
-		os.Exit(0)
+	// Default to the run command (and the interactive wizard) when no command is given
+	commandName := "run"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		commandName = args[0]
+		args = args[1:]
 	}
 
-	// Check packages
-	results, err := nxrmConnection.CheckPackages(repoName, format, nxiqConnection)
-	if err != nil {
-		cli.PrintCliln(fmt.Sprintf("Unexpected failure: %v", err), util.ColorRed)
-		os.Exit(1)
+	cmd := findCommand(commandName)
+	if cmd == nil {
+		cli.PrintCliln(fmt.Sprintf("Error: Unknown command '%s'", commandName), util.ColorRed)
+		printUsage()
+		os.Exit(exitRunError)
 	}
 
-	// Display results
-	displayResults(results, format)
+	cmd.run(args)
 }
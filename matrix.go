@@ -0,0 +1,231 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/cli"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/report"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/util"
+)
+
+// matrixCell is the coverage of one Reference Policy for one format
+type matrixCell struct {
+	packages int
+	tested   int
+	outcome  report.Outcome
+}
+
+// outcomeSeverity orders outcomes so that the worst of several results is shown in a cell
+var outcomeSeverity = map[report.Outcome]int{
+	report.OutcomeMet:          1,
+	report.OutcomeInconclusive: 2,
+	report.OutcomeMismatch:     3,
+	report.OutcomeGap:          4,
+}
+
+// matrixCommand displays a grid of which Reference Policies can be tested for each format, optionally
+// filled with the outcomes from a JSON report
+func matrixCommand(args []string) {
+	flags := newFlagSet("matrix")
+	resultsPath := flags.String("results", "", "JSON report of a run - fills each cell with the outcome of its packages")
+	outputFormat := flags.String("output-format", "terminal", "Output format: terminal, markdown or csv")
+	parseFlags(flags, args)
+
+	var results *report.Report
+	if *resultsPath != "" {
+		var err error
+		results, err = report.Load(*resultsPath)
+		if err != nil {
+			cli.PrintCliln(fmt.Sprintf("Error: Invalid results: %v", err), util.ColorRed)
+			os.Exit(exitRunError)
+		}
+	}
+	grid := buildMatrix(allSupportedFormats, results)
+	withResults := results != nil
+
+	switch *outputFormat {
+	case "terminal":
+		printMatrixTerminal(grid, withResults)
+	case "markdown":
+		printMatrixMarkdown(grid, withResults)
+	case "csv":
+		printMatrixCSV(grid, withResults)
+	default:
+		cli.PrintCliln(fmt.Sprintf("Error: Unsupported output format '%s' - expected terminal, markdown or csv", *outputFormat), util.ColorRed)
+		os.Exit(exitRunError)
+	}
+}
+
+// buildMatrix counts the test packages of each Reference Policy for each format and, if results are given,
+// fills each cell with the worst outcome of its results
+func buildMatrix(packageFormats []formats.PackageFormat, results *report.Report) map[formats.PolicyName]map[string]*matrixCell {
+	grid := make(map[formats.PolicyName]map[string]*matrixCell)
+	for _, policy := range formats.AllPolicyNames {
+		grid[policy] = make(map[string]*matrixCell)
+		for _, format := range packageFormats {
+			grid[policy][format.GetName()] = &matrixCell{}
+		}
+	}
+	for _, format := range packageFormats {
+		for _, pkg := range format.GetPackages() {
+			if cell, ok := grid[pkg.PolicyName][format.GetName()]; ok {
+				cell.packages++
+			}
+		}
+	}
+
+	if results == nil {
+		return grid
+	}
+	for _, run := range results.Runs {
+		for _, result := range run.Results {
+			if cell, ok := grid[result.ExpectedPolicy][run.Format]; ok {
+				cell.add(result.Outcome)
+			}
+		}
+	}
+	return grid
+}
+
+// add records the outcome of a result, keeping the worst outcome of the cell
+func (c *matrixCell) add(outcome report.Outcome) {
+	c.tested++
+	if outcomeSeverity[outcome] > outcomeSeverity[c.outcome] {
+		c.outcome = outcome
+	}
+}
+
+// matrixCellText describes a cell - the outcome of its results, "not tested" if results were given without
+// any for the cell, otherwise the count of test packages
+func matrixCellText(cell *matrixCell, withResults bool) string {
+	switch {
+	case cell.tested > 0:
+		return strings.ToUpper(string(cell.outcome))
+	case cell.packages > 0 && withResults:
+		return "not tested"
+	case cell.packages > 0:
+		return fmt.Sprintf("%d", cell.packages)
+	default:
+		return "-"
+	}
+}
+
+// matrixCellColor returns the terminal colour for a cell
+func matrixCellColor(cell *matrixCell) string {
+	switch {
+	case cell.tested == 0 && cell.packages > 0:
+		return util.ColorReset
+	case cell.tested == 0:
+		return util.ColorYellow
+	case cell.outcome == report.OutcomeMet:
+		return util.ColorGreen
+	case cell.outcome == report.OutcomeInconclusive:
+		return util.ColorYellow
+	default:
+		return util.ColorRed
+	}
+}
+
+func printMatrixTerminal(grid map[formats.PolicyName]map[string]*matrixCell, withResults bool) {
+	header := fmt.Sprintf("%30s", "Reference Policy")
+	for _, format := range allSupportedFormats {
+		header += fmt.Sprintf(" %12s", format.GetName())
+	}
+	cli.PrintCliln(header, util.ColorYellow)
+
+	for _, policy := range formats.AllPolicyNames {
+		line := fmt.Sprintf("%s%30s%s", policy.GetSecurityColor(), policy, util.ColorReset)
+		for _, format := range allSupportedFormats {
+			cell := grid[policy][format.GetName()]
+			line += fmt.Sprintf(" %s%12s%s", matrixCellColor(cell), matrixCellText(cell, withResults), util.ColorReset)
+		}
+		cli.PrintCliln(line, util.ColorReset)
+	}
+
+	if withResults {
+		cli.PrintCliln("\nCells show the worst outcome of their results; - means the policy cannot be tested for that format.", util.ColorReset)
+		return
+	}
+	cli.PrintCliln("\nNumbers are the count of test packages; - means the policy cannot be tested for that format.", util.ColorReset)
+}
+
+func printMatrixMarkdown(grid map[formats.PolicyName]map[string]*matrixCell, withResults bool) {
+	header := "| Reference Policy |"
+	divider := "| ---------------- |"
+	for _, format := range allSupportedFormats {
+		header += fmt.Sprintf(" %s |", format.GetDisplayName())
+		divider += " :---: |"
+	}
+	fmt.Println(header)
+	fmt.Println(divider)
+
+	for _, policy := range formats.AllPolicyNames {
+		line := fmt.Sprintf("| `%s` |", policy)
+		for _, format := range allSupportedFormats {
+			cell := grid[policy][format.GetName()]
+			var text string
+			switch {
+			case cell.tested > 0 && cell.outcome == report.OutcomeMet:
+				text = "✅ met"
+			case cell.tested > 0 && cell.outcome == report.OutcomeInconclusive:
+				text = "❔ inconclusive"
+			case cell.tested > 0:
+				text = fmt.Sprintf("❌ %s", cell.outcome)
+			case cell.packages > 0 && withResults:
+				text = "not tested"
+			case cell.packages > 0:
+				text = "✅"
+			default:
+				text = "❌"
+			}
+			line += fmt.Sprintf(" %s |", text)
+		}
+		fmt.Println(line)
+	}
+}
+
+func printMatrixCSV(grid map[formats.PolicyName]map[string]*matrixCell, withResults bool) {
+	w := csv.NewWriter(os.Stdout)
+	header := []string{"policy"}
+	for _, format := range allSupportedFormats {
+		header = append(header, format.GetName())
+	}
+	_ = w.Write(header)
+
+	for _, policy := range formats.AllPolicyNames {
+		record := []string{string(policy)}
+		for _, format := range allSupportedFormats {
+			cell := grid[policy][format.GetName()]
+			switch {
+			case cell.tested > 0:
+				record = append(record, string(cell.outcome))
+			case cell.packages > 0 && withResults:
+				record = append(record, "not tested")
+			default:
+				record = append(record, fmt.Sprintf("%d", cell.packages))
+			}
+		}
+		_ = w.Write(record)
+	}
+	w.Flush()
+}
@@ -0,0 +1,119 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/report"
+)
+
+func TestMatrixCellAdd(t *testing.T) {
+	tests := []struct {
+		name     string
+		outcomes []report.Outcome
+		want     report.Outcome
+	}{
+		{"single", []report.Outcome{report.OutcomeMet}, report.OutcomeMet},
+		{"gap beats met", []report.Outcome{report.OutcomeMet, report.OutcomeGap, report.OutcomeMet}, report.OutcomeGap},
+		{"gap beats mismatch", []report.Outcome{report.OutcomeGap, report.OutcomeMismatch}, report.OutcomeGap},
+		{"mismatch beats inconclusive", []report.Outcome{report.OutcomeInconclusive, report.OutcomeMismatch}, report.OutcomeMismatch},
+		{"inconclusive beats met", []report.Outcome{report.OutcomeMet, report.OutcomeInconclusive}, report.OutcomeInconclusive},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cell := &matrixCell{packages: 1}
+			for _, outcome := range test.outcomes {
+				cell.add(outcome)
+			}
+			if cell.outcome != test.want || cell.tested != len(test.outcomes) {
+				t.Errorf("cell = %s from %d results, want %s from %d", cell.outcome, cell.tested, test.want, len(test.outcomes))
+			}
+		})
+	}
+}
+
+func TestBuildMatrix(t *testing.T) {
+	npm := formats.NPMFormat{}
+	results := &report.Report{Runs: []report.Run{
+		{Format: npm.GetName(), Repository: "npm-proxy", Results: []report.Result{
+			{ExpectedPolicy: formats.SecurityCritical, Outcome: report.OutcomeMet},
+			{ExpectedPolicy: formats.SecurityCritical, Outcome: report.OutcomeGap},
+			{ExpectedPolicy: formats.SecurityHigh, Outcome: report.OutcomeMet},
+		}},
+		{Format: "unknown", Repository: "unknown-proxy", Results: []report.Result{
+			{ExpectedPolicy: formats.SecurityCritical, Outcome: report.OutcomeMismatch},
+		}},
+	}}
+
+	packages := make(map[formats.PolicyName]int)
+	for _, pkg := range npm.GetPackages() {
+		packages[pkg.PolicyName]++
+	}
+
+	tests := []struct {
+		name        string
+		results     *report.Report
+		policy      formats.PolicyName
+		wantTested  int
+		wantOutcome report.Outcome
+		wantText    string
+	}{
+		{"packages without results", nil, formats.SecurityCritical, 0, "", "1"},
+		{"worst outcome of results", results, formats.SecurityCritical, 2, report.OutcomeGap, "GAP"},
+		{"met", results, formats.SecurityHigh, 1, report.OutcomeMet, "MET"},
+		{"not tested", results, formats.SecurityMedium, 0, "", "not tested"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			grid := buildMatrix([]formats.PackageFormat{npm}, test.results)
+			cell := grid[test.policy][npm.GetName()]
+			if cell.packages != packages[test.policy] {
+				t.Errorf("packages = %d, want %d", cell.packages, packages[test.policy])
+			}
+			if cell.tested != test.wantTested || cell.outcome != test.wantOutcome {
+				t.Errorf("cell = %s from %d results, want %s from %d", cell.outcome, cell.tested, test.wantOutcome, test.wantTested)
+			}
+			if text := matrixCellText(cell, test.results != nil); text != test.wantText {
+				t.Errorf("matrixCellText() = %s, want %s", text, test.wantText)
+			}
+		})
+	}
+}
+
+func TestMatrixCellText(t *testing.T) {
+	tests := []struct {
+		name        string
+		cell        matrixCell
+		withResults bool
+		want        string
+	}{
+		{"test data", matrixCell{packages: 2}, false, "2"},
+		{"no test data", matrixCell{}, false, "-"},
+		{"no test data with results", matrixCell{}, true, "-"},
+		{"not tested", matrixCell{packages: 2}, true, "not tested"},
+		{"tested", matrixCell{packages: 2, tested: 2, outcome: report.OutcomeMismatch}, true, "MISMATCH"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := matrixCellText(&test.cell, test.withResults); got != test.want {
+				t.Errorf("matrixCellText() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/report"
+)
+
+// Kind is the type of service a notification is sent to
+type Kind string
+
+const (
+	KindWebhook Kind = "webhook"
+	KindSlack   Kind = "slack"
+	KindTeams   Kind = "teams"
+)
+
+// AllKinds lists every supported kind of notification
+var AllKinds = []Kind{KindWebhook, KindSlack, KindTeams}
+
+// When controls whether a notification is sent for a run
+type When string
+
+const (
+	// WhenAlways sends a notification after every run
+	WhenAlways When = "always"
+	// WhenProblems only sends a notification if the run failed or regressed since the baseline
+	WhenProblems When = "problems"
+)
+
+// defaultTextTemplate is the message sent to Slack and Microsoft Teams unless a template is given
+const defaultTextTemplate = `{{ if .Passed }}✅{{ else }}❌{{ end }} Sonatype Repository Firewall policy test against {{ .NxrmURL }}: {{ .Counts.Available }} downloadable, {{ .Counts.Quarantined }} quarantined, {{ .Counts.Failed }} failed
+{{- if .Regressions }}
+
+{{ len .Regressions }} package(s) are newly downloadable since the baseline:
+{{- range .Regressions }}
+• {{ .Format }}/{{ .Repository }}: {{ .Package }} [{{ .ExpectedPolicy }}]
+{{- end }}
+{{- end }}
+{{- if .Problems }}
+
+{{ len .Problems }} result(s) did not meet expectations:
+{{- range .Problems }}
+• {{ .Format }}/{{ .Repository }}: {{ .Package }} [{{ .ExpectedPolicy }}] - {{ .Outcome }} ({{ .Status }})
+{{- end }}
+{{- end }}`
+
+// defaultContentType is sent unless a webhook target gives its own
+const defaultContentType = "application/json"
+
+// Target is somewhere to send notifications
+type Target struct {
+	Kind     Kind
+	URL      string
+	When     When
+	Template *template.Template
+	// ContentType of a webhook body rendered from Template - bodies sent as JSON must be valid JSON
+	ContentType string
+}
+
+// Summary is the outcome of a run, as sent to webhooks and available to templates
+type Summary struct {
+	Tool        report.ToolInfo `json:"tool"`
+	NxrmURL     string          `json:"nxrmUrl"`
+	IqURL       string          `json:"iqUrl"`
+	StartedAt   time.Time       `json:"startedAt"`
+	FinishedAt  time.Time       `json:"finishedAt"`
+	Passed      bool            `json:"passed"`
+	Counts      report.Counts   `json:"counts"`
+	Outcomes    map[string]int  `json:"outcomes"`
+	Problems    []Problem       `json:"problems"`
+	Regressions []report.Change `json:"regressions"`
+	Report      *report.Report  `json:"-"`
+}
+
+// Problem is a result that did not meet expectations
+type Problem struct {
+	Format         string `json:"format"`
+	Repository     string `json:"repository"`
+	Package        string `json:"package"`
+	ExpectedPolicy string `json:"expectedPolicy"`
+	Status         string `json:"status"`
+	Outcome        string `json:"outcome"`
+}
+
+// NewSummary summarises a run - outcomes in failOn are problems, as they are for the exit code
+func NewSummary(r *report.Report, failOn map[report.Outcome]bool) Summary {
+	summary := Summary{
+		Tool:        r.Tool,
+		NxrmURL:     r.NxrmURL,
+		IqURL:       r.IqURL,
+		StartedAt:   r.StartedAt,
+		FinishedAt:  r.FinishedAt,
+		Outcomes:    map[string]int{},
+		Problems:    []Problem{},
+		Regressions: []report.Change{},
+		Report:      r,
+	}
+
+	for _, run := range r.Runs {
+		counts := run.Counts()
+		summary.Counts.Available += counts.Available
+		summary.Counts.Cached += counts.Cached
+		summary.Counts.Quarantined += counts.Quarantined
+		summary.Counts.Failed += counts.Failed
+		summary.Counts.Total += counts.Total
+
+		for _, result := range run.Results {
+			summary.Outcomes[string(result.Outcome)]++
+			if failOn[result.Outcome] {
+				summary.Problems = append(summary.Problems, Problem{
+					Format:         run.Format,
+					Repository:     run.Repository,
+					Package:        result.Package.DisplayName,
+					ExpectedPolicy: string(result.ExpectedPolicy),
+					Status:         string(result.Status),
+					Outcome:        string(result.Outcome),
+				})
+			}
+		}
+	}
+
+	if r.Baseline != nil {
+		for _, change := range r.Baseline.Changes {
+			if change.Kind == report.ChangeNewlyAvailable {
+				summary.Regressions = append(summary.Regressions, change)
+			}
+		}
+	}
+
+	summary.Passed = len(summary.Problems) == 0 && len(summary.Regressions) == 0
+	return summary
+}
+
+// ParseKind returns the named Kind
+func ParseKind(name string) (Kind, error) {
+	var names []string
+	for _, kind := range AllKinds {
+		if string(kind) == name {
+			return kind, nil
+		}
+		names = append(names, string(kind))
+	}
+	return "", fmt.Errorf("unsupported notification type '%s' - expected one of: %s", name, strings.Join(names, ", "))
+}
+
+// ParseWhen returns the named When
+func ParseWhen(name string) (When, error) {
+	switch When(name) {
+	case WhenAlways, WhenProblems:
+		return When(name), nil
+	}
+	return "", fmt.Errorf("unsupported notification condition '%s' - expected always or problems", name)
+}
+
+// ParseTemplate parses a message template - see Summary for the data available to it
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("notification").Option("missingkey=error").Parse(text)
+}
+
+// ShouldNotify returns true if a notification should be sent to this target for the run
+func (t Target) ShouldNotify(summary Summary) bool {
+	return t.When != WhenProblems || !summary.Passed
+}
+
+// Send posts the summary to the target
+func (t Target) Send(client *http.Client, summary Summary) error {
+	body, err := t.payload(summary)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Post(t.URL, t.contentType(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", redact(t.URL), resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// payload builds the request body for the kind of target
+func (t Target) payload(summary Summary) ([]byte, error) {
+	if t.Kind == KindWebhook && t.Template == nil {
+		return json.Marshal(summary)
+	}
+
+	tmpl := t.Template
+	if tmpl == nil {
+		tmpl = template.Must(ParseTemplate(defaultTextTemplate))
+	}
+	var text bytes.Buffer
+	if err := tmpl.Execute(&text, summary); err != nil {
+		return nil, fmt.Errorf("failed to render notification template: %w", err)
+	}
+
+	switch t.Kind {
+	case KindSlack:
+		return json.Marshal(map[string]string{"text": text.String()})
+	case KindTeams:
+		return json.Marshal(teamsMessage(summary, text.String()))
+	default:
+		if t.contentType() == defaultContentType && !json.Valid(text.Bytes()) {
+			return nil, fmt.Errorf("notification template did not produce valid JSON - set a content type to send other bodies")
+		}
+		return text.Bytes(), nil
+	}
+}
+
+// contentType returns the Content-Type of the request body
+func (t Target) contentType() string {
+	if t.Kind == KindWebhook && t.Template != nil && t.ContentType != "" {
+		return t.ContentType
+	}
+	return defaultContentType
+}
+
+// teamsMessage wraps text in an Adaptive Card, as accepted by Microsoft Teams Workflows webhooks
+func teamsMessage(summary Summary, text string) map[string]interface{} {
+	title, color := "Firewall policy test passed", "Good"
+	if !summary.Passed {
+		title, color = "Firewall policy test failed", "Attention"
+	}
+
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body": []map[string]interface{}{
+						{"type": "TextBlock", "text": title, "weight": "Bolder", "size": "Medium", "color": color},
+						// Teams only breaks lines between paragraphs
+						{"type": "TextBlock", "text": strings.ReplaceAll(text, "\n", "\n\n"), "wrap": true},
+					},
+				},
+			},
+		},
+	}
+}
+
+// redact removes the path of a webhook URL, which usually contains its secret
+func redact(url string) string {
+	scheme, rest, ok := strings.Cut(url, "://")
+	if !ok {
+		return "webhook"
+	}
+	host, _, _ := strings.Cut(rest, "/")
+	return scheme + "://" + host + "/..."
+}
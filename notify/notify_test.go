@@ -0,0 +1,279 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/report"
+)
+
+// request is what a test webhook received
+type request struct {
+	contentType string
+	body        []byte
+}
+
+// newWebhook starts a server that records the requests posted to it
+func newWebhook(t *testing.T) (*httptest.Server, *[]request) {
+	t.Helper()
+	var received []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, request{contentType: r.Header.Get("Content-Type"), body: body})
+	}))
+	t.Cleanup(server.Close)
+	return server, &received
+}
+
+// mustParseTemplate parses a notification template, failing the test if it is invalid
+func mustParseTemplate(t *testing.T, text string) *template.Template {
+	t.Helper()
+	tmpl, err := ParseTemplate(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tmpl
+}
+
+// testReport returns a report with one met and one gap result
+func testReport(regressed bool) *report.Report {
+	r := &report.Report{
+		NxrmURL: "https://nexus.example.com",
+		Runs: []report.Run{{
+			Format:     "npm",
+			Repository: "npm-proxy",
+			Results: []report.Result{
+				{Package: report.PackageInfo{DisplayName: "bson@1.0.9"}, ExpectedPolicy: formats.SecurityCritical, Status: formats.StatusQuarantined, Outcome: report.OutcomeMet},
+				{Package: report.PackageInfo{DisplayName: "braces@1.8.5"}, ExpectedPolicy: formats.SecurityHigh, Status: formats.StatusAvailable, Outcome: report.OutcomeGap},
+			},
+		}},
+	}
+	if regressed {
+		r.Baseline = &report.Comparison{Changes: []report.Change{
+			{Kind: report.ChangeNewlyAvailable, Format: "npm", Repository: "npm-proxy", Package: "braces@1.8.5", ExpectedPolicy: formats.SecurityHigh},
+			{Kind: report.ChangeNewlyQuarantined, Format: "npm", Repository: "npm-proxy", Package: "bson@1.0.9", ExpectedPolicy: formats.SecurityCritical},
+		}}
+	}
+	return r
+}
+
+func TestNewSummary(t *testing.T) {
+	summary := NewSummary(testReport(true), map[report.Outcome]bool{report.OutcomeGap: true})
+	if summary.Passed {
+		t.Error("Passed = true, want false")
+	}
+	if summary.Counts.Total != 2 || summary.Counts.Available != 1 || summary.Counts.Quarantined != 1 {
+		t.Errorf("Counts = %+v", summary.Counts)
+	}
+	if len(summary.Problems) != 1 || summary.Problems[0].Package != "braces@1.8.5" {
+		t.Errorf("Problems = %+v", summary.Problems)
+	}
+	if len(summary.Regressions) != 1 || summary.Regressions[0].Kind != report.ChangeNewlyAvailable {
+		t.Errorf("Regressions = %+v", summary.Regressions)
+	}
+}
+
+func TestShouldNotify(t *testing.T) {
+	tests := []struct {
+		name      string
+		when      When
+		failOn    map[report.Outcome]bool
+		regressed bool
+		want      bool
+	}{
+		{"always when passed", WhenAlways, nil, false, true},
+		{"always with problems", WhenAlways, map[report.Outcome]bool{report.OutcomeGap: true}, false, true},
+		{"problems when passed", WhenProblems, nil, false, false},
+		{"problems with problems", WhenProblems, map[report.Outcome]bool{report.OutcomeGap: true}, false, true},
+		{"problems with regressions", WhenProblems, nil, true, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			target := Target{Kind: KindWebhook, When: test.when}
+			if got := target.ShouldNotify(NewSummary(testReport(test.regressed), test.failOn)); got != test.want {
+				t.Errorf("ShouldNotify() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSend(t *testing.T) {
+	summary := NewSummary(testReport(false), map[report.Outcome]bool{report.OutcomeGap: true})
+	tests := []struct {
+		name            string
+		kind            Kind
+		template        string
+		contentType     string
+		wantContentType string
+		check           func(t *testing.T, body []byte)
+	}{
+		{
+			name:            "webhook",
+			kind:            KindWebhook,
+			wantContentType: "application/json",
+			check: func(t *testing.T, body []byte) {
+				var got Summary
+				if err := json.Unmarshal(body, &got); err != nil {
+					t.Fatal(err)
+				}
+				if got.Passed || got.NxrmURL != "https://nexus.example.com" || len(got.Problems) != 1 {
+					t.Errorf("body = %s", body)
+				}
+			},
+		},
+		{
+			name:            "slack",
+			kind:            KindSlack,
+			wantContentType: "application/json",
+			check: func(t *testing.T, body []byte) {
+				var got map[string]string
+				if err := json.Unmarshal(body, &got); err != nil {
+					t.Fatal(err)
+				}
+				if !strings.HasPrefix(got["text"], "❌") || !strings.Contains(got["text"], "npm/npm-proxy: braces@1.8.5 [Security-High] - gap") {
+					t.Errorf("text = %q", got["text"])
+				}
+			},
+		},
+		{
+			name:            "teams",
+			kind:            KindTeams,
+			wantContentType: "application/json",
+			check: func(t *testing.T, body []byte) {
+				var got struct {
+					Type        string `json:"type"`
+					Attachments []struct {
+						ContentType string `json:"contentType"`
+						Content     struct {
+							Body []struct {
+								Text string `json:"text"`
+							} `json:"body"`
+						} `json:"content"`
+					} `json:"attachments"`
+				}
+				if err := json.Unmarshal(body, &got); err != nil {
+					t.Fatal(err)
+				}
+				if got.Type != "message" || len(got.Attachments) != 1 || len(got.Attachments[0].Content.Body) != 2 {
+					t.Fatalf("body = %s", body)
+				}
+				if title := got.Attachments[0].Content.Body[0].Text; title != "Firewall policy test failed" {
+					t.Errorf("title = %q", title)
+				}
+			},
+		},
+		{
+			name:            "slack template",
+			kind:            KindSlack,
+			template:        `{{ .Counts.Quarantined }} of {{ .Counts.Total }} quarantined`,
+			wantContentType: "application/json",
+			check: func(t *testing.T, body []byte) {
+				if string(body) != `{"text":"1 of 2 quarantined"}` {
+					t.Errorf("body = %s", body)
+				}
+			},
+		},
+		{
+			name:            "webhook template",
+			kind:            KindWebhook,
+			template:        `{"passed": {{ .Passed }}, "problems": {{ len .Problems }}}`,
+			wantContentType: "application/json",
+			check: func(t *testing.T, body []byte) {
+				if string(body) != `{"passed": false, "problems": 1}` {
+					t.Errorf("body = %s", body)
+				}
+			},
+		},
+		{
+			name:            "webhook template with content type",
+			kind:            KindWebhook,
+			template:        `passed={{ .Passed }}`,
+			contentType:     "text/plain",
+			wantContentType: "text/plain",
+			check: func(t *testing.T, body []byte) {
+				if string(body) != "passed=false" {
+					t.Errorf("body = %s", body)
+				}
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, received := newWebhook(t)
+			target := Target{Kind: test.kind, URL: server.URL, ContentType: test.contentType}
+			if test.template != "" {
+				target.Template = mustParseTemplate(t, test.template)
+			}
+
+			if err := target.Send(server.Client(), summary); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if len(*received) != 1 {
+				t.Fatalf("received %d requests, want 1", len(*received))
+			}
+			got := (*received)[0]
+			if got.contentType != test.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", got.contentType, test.wantContentType)
+			}
+			test.check(t, got.body)
+		})
+	}
+}
+
+func TestSendErrors(t *testing.T) {
+	summary := NewSummary(testReport(false), nil)
+	tests := []struct {
+		name     string
+		template string
+		status   int
+		want     string
+	}{
+		{"invalid JSON", `passed={{ .Passed }}`, http.StatusOK, "did not produce valid JSON"},
+		{"missing key", `{{ .Outcomes.unknown.count }}`, http.StatusOK, "failed to render notification template"},
+		{"error response", "", http.StatusForbidden, "403 Forbidden"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			target := Target{Kind: KindWebhook, URL: server.URL + "/secret"}
+			if test.template != "" {
+				target.Template = mustParseTemplate(t, test.template)
+			}
+			err := target.Send(server.Client(), summary)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("Send() error = %v, want %q", err, test.want)
+			}
+			if strings.Contains(err.Error(), "secret") {
+				t.Errorf("Send() error = %v, exposes the webhook path", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nxiq
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+)
+
+// FindingKind is the way a policy differs from the Reference Policy Set
+type FindingKind string
+
+const (
+	FindingMissing            FindingKind = "missing"
+	FindingRenamed            FindingKind = "renamed"
+	FindingThreatLevel        FindingKind = "threat-level"
+	FindingMissingConstraints FindingKind = "missing-constraints"
+	FindingProxyAction        FindingKind = "proxy-action"
+)
+
+// LetsPackagesThrough returns true if the difference means packages the Reference Policy would quarantine
+// can be downloaded
+func (k FindingKind) LetsPackagesThrough() bool {
+	return k == FindingMissing || k == FindingMissingConstraints || k == FindingProxyAction
+}
+
+// Finding describes one way the policies in Sonatype IQ Server differ from the Reference Policy Set
+type Finding struct {
+	Reference formats.PolicyName
+	Kind      FindingKind
+	Policy    string
+	Detail    string
+}
+
+// AuditPolicies compares the policies that apply to Proxy Repositories with the Reference Policy Set
+func (c *NxiqConnection) AuditPolicies(policies []Policy) []Finding {
+	var findings []Finding
+	for _, reference := range formats.AllPolicyNames {
+		if reference == formats.None {
+			continue
+		}
+
+		matched := c.referencePolicies(reference, policies)
+		if len(matched) == 0 {
+			findings = append(findings, Finding{
+				Reference: reference,
+				Kind:      FindingMissing,
+				Detail:    "no policy takes the place of the Reference Policy",
+			})
+			continue
+		}
+
+		for _, policy := range matched {
+			if policy.Name != string(reference) {
+				findings = append(findings, Finding{
+					Reference: reference,
+					Kind:      FindingRenamed,
+					Policy:    policy.Name,
+					Detail:    fmt.Sprintf("named %s", policy.Name),
+				})
+			}
+
+			if level, ok := formats.ReferenceThreatLevels[reference]; ok && policy.ThreatLevel != level {
+				findings = append(findings, Finding{
+					Reference: reference,
+					Kind:      FindingThreatLevel,
+					Policy:    policy.Name,
+					Detail:    fmt.Sprintf("threat level %d - expected %d", policy.ThreatLevel, level),
+				})
+			}
+
+			switch policy.ProxyAction {
+			case ProxyActionUnknown:
+				findings = append(findings, Finding{
+					Reference: reference,
+					Kind:      FindingProxyAction,
+					Policy:    policy.Name,
+					Detail:    "Proxy stage action and constraints could not be read",
+				})
+				continue
+			case ProxyActionWarn:
+				findings = append(findings, Finding{
+					Reference: reference,
+					Kind:      FindingProxyAction,
+					Policy:    policy.Name,
+					Detail:    "Proxy stage action is not Fail",
+				})
+			}
+
+			if len(policy.Constraints) == 0 {
+				findings = append(findings, Finding{
+					Reference: reference,
+					Kind:      FindingMissingConstraints,
+					Policy:    policy.Name,
+					Detail:    "has no constraints, so can never be violated",
+				})
+			}
+			for _, constraint := range policy.Constraints {
+				if constraint.Conditions == 0 {
+					findings = append(findings, Finding{
+						Reference: reference,
+						Kind:      FindingMissingConstraints,
+						Policy:    policy.Name,
+						Detail:    fmt.Sprintf("constraint '%s' has no conditions", constraint.Name),
+					})
+				}
+			}
+			for _, name := range missingConstraints(reference, policy.Constraints) {
+				findings = append(findings, Finding{
+					Reference: reference,
+					Kind:      FindingMissingConstraints,
+					Policy:    policy.Name,
+					Detail:    fmt.Sprintf("has no constraint like the Reference Policy's '%s'", name),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// referencePolicies returns the policies that take the place of the Reference Policy - by name, using the
+// policy mapping, or renamed only by case and punctuation
+func (c *NxiqConnection) referencePolicies(reference formats.PolicyName, policies []Policy) []Policy {
+	var matched []Policy
+	for _, policy := range policies {
+		if c.policyMapping.Matches(reference, policy.Name) || normalizePolicyName(policy.Name) == normalizePolicyName(string(reference)) {
+			matched = append(matched, policy)
+		}
+	}
+	return matched
+}
+
+// missingConstraints returns the names of the constraints of the Reference Policy that none of the
+// constraints of a policy taking its place match
+func missingConstraints(reference formats.PolicyName, constraints []PolicyConstraint) []string {
+	var missing []string
+	for _, name := range formats.ReferenceConstraints[reference] {
+		found := false
+		for _, constraint := range constraints {
+			if strings.Contains(normalizePolicyName(constraint.Name), normalizePolicyName(name)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// normalizePolicyName lower cases a policy name and removes everything but letters and digits
+func normalizePolicyName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
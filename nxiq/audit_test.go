@@ -0,0 +1,105 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nxiq
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+)
+
+func TestAuditPoliciesThreatLevel(t *testing.T) {
+	constraints := []PolicyConstraint{{Name: "CVSS score", Conditions: 1}}
+	tests := []struct {
+		name      string
+		policy    Policy
+		wantFound bool
+	}{
+		{"reference level", Policy{Name: "Security-High", ThreatLevel: 9, ProxyAction: ProxyActionFail, Constraints: constraints}, false},
+		{"reference level medium", Policy{Name: "Security-Medium", ThreatLevel: 7, ProxyAction: ProxyActionFail, Constraints: constraints}, false},
+		{"lowered level", Policy{Name: "Security-High", ThreatLevel: 6, ProxyAction: ProxyActionFail, Constraints: constraints}, true},
+		{"no confirmed reference level", Policy{Name: "Security-Low", ThreatLevel: 1, ProxyAction: ProxyActionFail, Constraints: constraints}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &NxiqConnection{}
+			found := false
+			for _, finding := range c.AuditPolicies([]Policy{test.policy}) {
+				if finding.Kind == FindingThreatLevel {
+					found = true
+				}
+			}
+			if found != test.wantFound {
+				t.Errorf("threat-level finding = %v, want %v", found, test.wantFound)
+			}
+		})
+	}
+}
+
+func TestAuditPoliciesConstraints(t *testing.T) {
+	c := &NxiqConnection{}
+	findings := c.AuditPolicies([]Policy{
+		{Name: string(formats.SecurityCritical), ThreatLevel: 10, ProxyAction: ProxyActionFail, Constraints: []PolicyConstraint{{Name: "Empty", Conditions: 0}}},
+	})
+	for _, finding := range findings {
+		if finding.Reference == formats.SecurityCritical && finding.Kind == FindingMissingConstraints {
+			return
+		}
+	}
+	t.Errorf("expected a missing-constraints finding for a constraint with no conditions, got %v", findings)
+}
+
+func TestAuditPoliciesReferenceConstraints(t *testing.T) {
+	tests := []struct {
+		name        string
+		reference   formats.PolicyName
+		constraints []string
+		wantMissing []string
+	}{
+		{"reference constraint", formats.SecurityCritical, []string{"Critical risk CVSS score"}, nil},
+		{"renamed by case and punctuation", formats.SecurityHigh, []string{"high-risk cvss score"}, nil},
+		{"extra constraints", formats.SecurityMedium, []string{"Medium risk CVSS score", "Exploitable"}, nil},
+		{"other constraint", formats.SecurityLow, []string{"CVSS score"}, []string{"Low risk CVSS score"}},
+		{"one of two missing", formats.IntegrityRating, []string{"Integrity Rating Suspicious"}, []string{"Pending"}},
+		{"both present", formats.IntegrityRating, []string{"Integrity Rating Suspicious", "Integrity Rating Pending"}, nil},
+		{"no reference constraints", formats.LicenseBanned, []string{"Banned"}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var constraints []PolicyConstraint
+			for _, name := range test.constraints {
+				constraints = append(constraints, PolicyConstraint{Name: name, Conditions: 1})
+			}
+			missing := missingConstraints(test.reference, constraints)
+			if !slices.Equal(missing, test.wantMissing) {
+				t.Errorf("missingConstraints() = %v, want %v", missing, test.wantMissing)
+			}
+
+			c := &NxiqConnection{}
+			found := 0
+			for _, finding := range c.AuditPolicies([]Policy{{Name: string(test.reference), ProxyAction: ProxyActionFail, Constraints: constraints}}) {
+				if finding.Reference == test.reference && finding.Kind == FindingMissingConstraints {
+					found++
+				}
+			}
+			if found != len(test.wantMissing) {
+				t.Errorf("%d missing-constraints findings, want %d", found, len(test.wantMissing))
+			}
+		})
+	}
+}
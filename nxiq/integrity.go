@@ -0,0 +1,43 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nxiq
+
+import (
+	"strings"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+)
+
+// IntegrityStateOf returns the integrity state of a quarantined component, from the constraints its
+// Integrity-Rating violation (or the policy taking its place) matched
+func (c *NxiqConnection) IntegrityStateOf(component QuarantinedComponent) formats.IntegrityState {
+	state := formats.IntegrityQuarantined
+	for _, violation := range component.Violations {
+		if !c.policyMapping.Matches(formats.IntegrityRating, violation.PolicyName) {
+			continue
+		}
+		for _, constraint := range violation.Constraints {
+			switch name := strings.ToLower(constraint); {
+			case strings.Contains(name, "pending"):
+				return formats.IntegrityPending
+			case strings.Contains(name, "suspicious"):
+				state = formats.IntegritySuspicious
+			}
+		}
+	}
+	return state
+}
@@ -0,0 +1,45 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nxiq
+
+import (
+	"testing"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+)
+
+func TestIntegrityStateOf(t *testing.T) {
+	tests := []struct {
+		name       string
+		violations []QuarantineViolation
+		want       formats.IntegrityState
+	}{
+		{"pending", []QuarantineViolation{{PolicyName: "Integrity-Rating", Constraints: []string{"Integrity Rating is Pending"}}}, formats.IntegrityPending},
+		{"suspicious", []QuarantineViolation{{PolicyName: "Integrity-Rating", Constraints: []string{"Integrity Rating is Suspicious"}}}, formats.IntegritySuspicious},
+		{"other constraint", []QuarantineViolation{{PolicyName: "Integrity-Rating", Constraints: []string{"Hash mismatch"}}}, formats.IntegrityQuarantined},
+		{"other policy", []QuarantineViolation{{PolicyName: "Security-Critical", Constraints: []string{"Pending review"}}}, formats.IntegrityQuarantined},
+		{"no violations", nil, formats.IntegrityQuarantined},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &NxiqConnection{}
+			if got := c.IntegrityStateOf(QuarantinedComponent{Violations: test.violations}); got != test.want {
+				t.Errorf("IntegrityStateOf() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nxiq
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	nxiq "github.com/sonatype-nexus-community/nexus-iq-api-client-go"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+)
+
+const rootOrganizationId = "ROOT_ORGANIZATION_ID"
+
+// ProxyAction is what a policy does to components that violate it at the Proxy stage
+type ProxyAction string
+
+const (
+	ProxyActionFail ProxyAction = "fail"
+	ProxyActionWarn ProxyAction = "warn"
+	// ProxyActionUnknown - the policy is not part of the Root Organization policy export, so its actions
+	// could not be read
+	ProxyActionUnknown ProxyAction = ""
+)
+
+// Policy is a Sonatype IQ Server policy that applies to Proxy Repositories
+type Policy struct {
+	Id          string
+	Name        string
+	OwnerId     string
+	OwnerType   string
+	PolicyType  string
+	ThreatLevel int32
+	ProxyAction ProxyAction
+	// Constraints are only read for policies whose Proxy stage action is known
+	Constraints []PolicyConstraint
+}
+
+// PolicyConstraint is one of the sets of conditions that trigger a policy
+type PolicyConstraint struct {
+	Name       string
+	Conditions int
+}
+
+// policyExport is the subset of the Root Organization policy export needed to read policy actions - the
+// public policy API does not include them
+type policyExport struct {
+	Policies []struct {
+		Id          string            `json:"id"`
+		Name        string            `json:"name"`
+		Actions     map[string]string `json:"actions"`
+		Constraints []struct {
+			Name       string            `json:"name"`
+			Conditions []json.RawMessage `json:"conditions"`
+		} `json:"constraints"`
+	} `json:"policies"`
+}
+
+// appliesToRepositories returns true if policies of the owner are inherited by Proxy Repositories -
+// those of the Root Organization and the repository hierarchy
+func appliesToRepositories(ownerType, ownerId string) bool {
+	switch strings.ToUpper(ownerType) {
+	case "ORGANIZATION":
+		return ownerId == rootOrganizationId
+	case "REPOSITORY_CONTAINER", "REPOSITORY_MANAGER", "REPOSITORY":
+		return true
+	default:
+		return false
+	}
+}
+
+// GetRepositoryPolicies returns the policies that apply to Proxy Repositories with their Proxy stage action
+func (c *NxiqConnection) GetRepositoryPolicies() ([]Policy, error) {
+	policyList, apiResponse, err := c.apiClient.PoliciesAPI.GetPolicies(*c.ctx).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Sonatype IQ Server policies: %w", err)
+	}
+	if apiResponse.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list Sonatype IQ Server policies - status code: %d", apiResponse.StatusCode)
+	}
+
+	export, err := c.exportRootPolicies()
+	if err != nil {
+		return nil, err
+	}
+	actions := make(map[string]ProxyAction)
+	constraints := make(map[string][]PolicyConstraint)
+	for _, exported := range export.Policies {
+		actions[exported.Id] = ProxyAction(strings.ToLower(exported.Actions["proxy"]))
+		for _, constraint := range exported.Constraints {
+			constraints[exported.Id] = append(constraints[exported.Id], PolicyConstraint{
+				Name:       constraint.Name,
+				Conditions: len(constraint.Conditions),
+			})
+		}
+	}
+
+	var policies []Policy
+	for _, p := range policyList.Policies {
+		if !appliesToRepositories(p.GetOwnerType(), p.GetOwnerId()) {
+			continue
+		}
+		action, known := actions[p.GetId()]
+		if !known {
+			action = ProxyActionUnknown
+		} else if action != ProxyActionFail {
+			// No action at the Proxy stage lets components through, as a warning does
+			action = ProxyActionWarn
+		}
+		policies = append(policies, Policy{
+			Id:          p.GetId(),
+			Name:        p.GetName(),
+			OwnerId:     p.GetOwnerId(),
+			OwnerType:   p.GetOwnerType(),
+			PolicyType:  p.GetPolicyType(),
+			ThreatLevel: p.GetThreatLevel(),
+			ProxyAction: action,
+			Constraints: constraints[p.GetId()],
+		})
+	}
+
+	return policies, nil
+}
+
+// exportRootPolicies reads the Root Organization policy export, which includes the action of each policy
+// at every stage. The generated API client has no operation for the export, so the request is built here
+// from the client's configuration - server URL, credentials, user agent, default headers and HTTP client
+func (c *NxiqConnection) exportRootPolicies() (policyExport, error) {
+	var export policyExport
+
+	config := c.apiClient.GetConfig()
+	baseUrl, err := config.ServerURLWithContext(*c.ctx, "PoliciesAPIService.GetPolicies")
+	if err != nil {
+		return export, err
+	}
+	request, err := http.NewRequestWithContext(
+		*c.ctx,
+		http.MethodGet,
+		fmt.Sprintf("%s/rest/policy/organization/%s/export", baseUrl, rootOrganizationId),
+		nil,
+	)
+	if err != nil {
+		return export, err
+	}
+	if auth, ok := (*c.ctx).Value(nxiq.ContextBasicAuth).(nxiq.BasicAuth); ok {
+		request.SetBasicAuth(auth.UserName, auth.Password)
+	}
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("User-Agent", config.UserAgent)
+	for header, value := range config.DefaultHeader {
+		request.Header.Set(header, value)
+	}
+
+	client := config.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return export, fmt.Errorf("failed to export Sonatype IQ Server policies: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return export, fmt.Errorf("failed to export Sonatype IQ Server policies - status code: %d", response.StatusCode)
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(&export); err != nil {
+		return export, fmt.Errorf("failed to read Sonatype IQ Server policy export: %w", err)
+	}
+	return export, nil
+}
+
+// ExpectedActions works out what Sonatype Repository Firewall should do with packages of each Reference
+// Policy, given the policies in place - quarantine if a matching policy fails at the Proxy stage, warn-only
+// if matching policies only warn, and no-policy if no policy takes its place
+func (c *NxiqConnection) ExpectedActions(policies []Policy) map[formats.PolicyName]formats.ExpectedAction {
+	expected := make(map[formats.PolicyName]formats.ExpectedAction)
+	for _, reference := range formats.AllPolicyNames {
+		if reference == formats.None {
+			continue
+		}
+
+		action := formats.ExpectNoPolicy
+		for _, policy := range policies {
+			if !c.policyMapping.Matches(reference, policy.Name) {
+				continue
+			}
+			if policy.ProxyAction == ProxyActionWarn {
+				action = formats.ExpectWarnOnly
+				continue
+			}
+			// A policy whose actions could not be read is assumed to fail, as in the Reference Policy Set
+			action = formats.ExpectQuarantine
+			break
+		}
+		expected[reference] = action
+	}
+	return expected
+}
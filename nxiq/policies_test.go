@@ -0,0 +1,64 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nxiq
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	nxiq "github.com/sonatype-nexus-community/nexus-iq-api-client-go"
+)
+
+func TestExportRootPolicies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/policy/organization/ROOT_ORGANIZATION_ID/export" {
+			http.NotFound(w, r)
+			return
+		}
+		if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("User-Agent") != "policy-tester" {
+			t.Errorf("User-Agent = %q, want the client's", r.Header.Get("User-Agent"))
+		}
+		w.Write([]byte(`{"policies": [{"id": "p1", "name": "Security-High", "actions": {"proxy": "fail"},
+			"constraints": [{"name": "High risk CVSS score", "conditions": [{}, {}]}]}]}`))
+	}))
+	defer server.Close()
+
+	configuration := nxiq.NewConfiguration()
+	configuration.Servers = nxiq.ServerConfigurations{{URL: server.URL}}
+	configuration.UserAgent = "policy-tester"
+	ctx := context.WithValue(context.Background(), nxiq.ContextBasicAuth, nxiq.BasicAuth{UserName: "admin", Password: "secret"})
+	c := &NxiqConnection{apiClient: nxiq.NewAPIClient(configuration), ctx: &ctx, iqBaseUrl: server.URL}
+
+	export, err := c.exportRootPolicies()
+	if err != nil {
+		t.Fatalf("exportRootPolicies() error: %v", err)
+	}
+	if len(export.Policies) != 1 || export.Policies[0].Actions["proxy"] != "fail" || len(export.Policies[0].Constraints[0].Conditions) != 2 {
+		t.Errorf("exportRootPolicies() = %+v", export)
+	}
+
+	ctx = context.WithValue(context.Background(), nxiq.ContextBasicAuth, nxiq.BasicAuth{UserName: "admin", Password: "wrong"})
+	if _, err := c.exportRootPolicies(); err == nil {
+		t.Errorf("exportRootPolicies() with the wrong password succeeded")
+	}
+}
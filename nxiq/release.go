@@ -0,0 +1,98 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nxiq
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// QuarantinedComponent is a component quarantined in a repository, with the ID needed to release it
+type QuarantinedComponent struct {
+	QuarantineId   string
+	Hash           string
+	DisplayName    string
+	QuarantineTime *time.Time
+	RepositoryId   string // Sonatype IQ Server's ID for the repository, used to scope waivers
+	Violations     []QuarantineViolation
+}
+
+// QuarantineViolation is a policy violation that caused a component to be quarantined
+type QuarantineViolation struct {
+	PolicyViolationId string
+	PolicyName        string
+	Constraints       []string // Names of the policy constraints that were violated
+}
+
+// GetQuarantinedComponents returns the components quarantined in the repository, keyed by hash
+func (c *NxiqConnection) GetQuarantinedComponents(repositoryName string) (map[string]QuarantinedComponent, error) {
+	quarantine, apiResponse, err := c.apiClient.ReportsAPI.GetComponentsInQuarantine(*c.ctx).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list components in quarantine: %w", err)
+	}
+	if apiResponse.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list components in quarantine - status code: %d", apiResponse.StatusCode)
+	}
+
+	components := make(map[string]QuarantinedComponent)
+	for _, repository := range quarantine.ComponentsInQuarantine {
+		if repository.Repository.GetPublicId() != repositoryName {
+			continue
+		}
+		for _, violation := range repository.Components {
+			component := violation.GetComponent()
+			if component.GetQuarantineId() == "" || component.GetHash() == "" {
+				continue
+			}
+			var violations []QuarantineViolation
+			for _, policyViolation := range violation.PolicyViolations {
+				var constraints []string
+				for _, constraint := range policyViolation.ConstraintViolations {
+					constraints = append(constraints, constraint.GetConstraintName())
+				}
+				violations = append(violations, QuarantineViolation{
+					PolicyViolationId: policyViolation.GetPolicyViolationId(),
+					PolicyName:        policyViolation.GetPolicyName(),
+					Constraints:       constraints,
+				})
+			}
+			components[component.GetHash()] = QuarantinedComponent{
+				QuarantineId:   component.GetQuarantineId(),
+				Hash:           component.GetHash(),
+				DisplayName:    component.GetDisplayName(),
+				QuarantineTime: component.QuarantineTime,
+				RepositoryId:   repository.Repository.GetRepositoryId(),
+				Violations:     violations,
+			}
+		}
+	}
+	return components, nil
+}
+
+// ReleaseFromQuarantine releases a component from quarantine without evaluating it against policies again,
+// recording the comment against the release
+func (c *NxiqConnection) ReleaseFromQuarantine(quarantineId, comment string) error {
+	_, apiResponse, err := c.apiClient.RepositoriesAPI.ReleaseQuarantineWithoutReEval(*c.ctx, quarantineId).Body(comment).Execute()
+	if err != nil {
+		return fmt.Errorf("failed to release %s from quarantine: %w", quarantineId, err)
+	}
+	if apiResponse.StatusCode != http.StatusOK && apiResponse.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("failed to release %s from quarantine - status code: %d", quarantineId, apiResponse.StatusCode)
+	}
+	return nil
+}
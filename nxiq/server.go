@@ -20,20 +20,59 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 
 	nxiq "github.com/sonatype-nexus-community/nexus-iq-api-client-go"
 	"github.com/sonatype-nexus-community/nxfw-policy-tester/cli"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
 	"github.com/sonatype-nexus-community/nxfw-policy-tester/util"
 )
 
 type NxiqConnection struct {
-	apiClient *nxiq.APIClient
-	iqBaseUrl string
-	ctx       *context.Context
+	apiClient     *nxiq.APIClient
+	iqBaseUrl     string
+	ctx           *context.Context
+	policyMapping formats.PolicyMapping
 }
 
-func (c *NxiqConnection) RetrieveFWQuarantineStatus(componentName, componentVersion, repositoryName, expectedPolicy, format, repoBaseUrl string) (bool, bool, error) {
+// SetPolicyMapping sets the customer policy names that take the place of each Reference Policy when
+// checking why a component was quarantined
+func (c *NxiqConnection) SetPolicyMapping(mapping formats.PolicyMapping) {
+	c.policyMapping = mapping
+}
+
+// GetBaseUrl returns the URL of the Sonatype IQ Server
+func (c *NxiqConnection) GetBaseUrl() string {
+	return c.iqBaseUrl
+}
+
+// QuarantineStatus describes how Sonatype Repository Firewall quarantined a component
+type QuarantineStatus struct {
+	Quarantined             bool
+	ExpectedPolicyTriggered bool
+	Policies                []string
+	MatchedPolicy           string
+	QuarantinePageURL       string
+	Hash                    string
+}
+
+// QuarantinePageURL returns the Sonatype IQ Server page describing a component quarantined in a repository
+func (c *NxiqConnection) QuarantinePageURL(repositoryId, hash string) string {
+	if repositoryId == "" || hash == "" {
+		return ""
+	}
+	return fmt.Sprintf(
+		"%s/assets/index.html#/repository/%s/result?hash=%s",
+		strings.TrimSuffix(c.iqBaseUrl, "/"),
+		url.PathEscape(repositoryId),
+		url.QueryEscape(hash),
+	)
+}
+
+// RetrieveFWQuarantineStatus returns whether the component is quarantined, whether the expected policy was
+// one of those that quarantined it, and the names of all policies that quarantined it
+func (c *NxiqConnection) RetrieveFWQuarantineStatus(componentName, componentVersion, repositoryName, expectedPolicy, format, repoBaseUrl string) (QuarantineStatus, error) {
 	originalComponentName := componentName
 	originalComponentVersion := componentVersion
 
@@ -68,7 +107,7 @@ func (c *NxiqConnection) RetrieveFWQuarantineStatus(componentName, componentVers
 			if err != nil || apiResponse.StatusCode != http.StatusOK {
 				cli.PrintCliln("Error: Failed to query Sonatype Repository Firewall Container Quarantine List. Please check your credentials and URL.", util.ColorRed)
 				cli.PrintCliln(fmt.Sprintf("Details: %v", err), util.ColorRed)
-				return false, false, err
+				return QuarantineStatus{}, err
 			}
 			allResults = append(allResults, resp.Results...)
 			if page >= *resp.PageCount {
@@ -77,27 +116,26 @@ func (c *NxiqConnection) RetrieveFWQuarantineStatus(componentName, componentVers
 			page++
 		}
 
-		quarantined := false
+		status := QuarantineStatus{}
 		for _, r := range allResults {
 			// ApplicationPublicId == repo.hostname.tld-dockerhub-proxy-sonatypecommunity-docker-policy-demo-Integrity-Pending
 			if *r.ApplicationPublicId == expectedId {
-				quarantined = true
+				status.Quarantined = true
 				break
 			}
 		}
 
-		return quarantined, false, nil
+		return status, nil
 	}
 
 	fwResult, apiResponse, err := c.apiClient.FirewallAPI.GetQuarantineList(*c.ctx).ComponentName(componentName).RepositoryPublicId((repositoryName)).Execute()
 	if err != nil || apiResponse.StatusCode != http.StatusOK {
 		cli.PrintCliln("Error: Failed to query Sonatype Repository Firewall Quarantine List. Please check your credentials and URL.", util.ColorRed)
 		cli.PrintCliln(fmt.Sprintf("Details: %v", err), util.ColorRed)
-		return false, false, err
+		return QuarantineStatus{}, err
 	}
 
-	var expectedPolicyTriggered = false
-	var quarantined = false
+	status := QuarantineStatus{}
 
 	for _, r := range fwResult.Results {
 		var coordinates = r.ComponentIdentifier.GetCoordinates()
@@ -125,16 +163,23 @@ func (c *NxiqConnection) RetrieveFWQuarantineStatus(componentName, componentVers
 
 		if (packageName == componentName || packageName2 == componentName) && version == componentVersion {
 			if r.Quarantined != nil && *r.Quarantined {
-				quarantined = true
-				if *r.PolicyName == expectedPolicy {
-					expectedPolicyTriggered = true
-					break
+				status.Quarantined = true
+				status.Policies = append(status.Policies, r.GetPolicyName())
+				if c.policyMapping.Matches(formats.PolicyName(expectedPolicy), r.GetPolicyName()) {
+					status.ExpectedPolicyTriggered = true
+					status.MatchedPolicy = r.GetPolicyName()
+				}
+				if status.QuarantinePageURL == "" {
+					status.QuarantinePageURL = c.QuarantinePageURL(r.GetRepositoryId(), r.GetHash())
+				}
+				if status.Hash == "" {
+					status.Hash = r.GetHash()
 				}
 			}
 		}
 	}
 
-	return quarantined, expectedPolicyTriggered, nil
+	return status, nil
 }
 
 // Validate credentials by making a test call
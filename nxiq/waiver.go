@@ -0,0 +1,74 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nxiq
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	nxiq "github.com/sonatype-nexus-community/nexus-iq-api-client-go"
+)
+
+// waiverOwnerType scopes waivers to a single repository
+const waiverOwnerType = "repository"
+
+// WaiveViolation waives a policy violation for the exact component, scoped to its repository, and returns the ID
+// of the waiver. The waiver expires at the given time in case it is never deleted
+func (c *NxiqConnection) WaiveViolation(repositoryId string, violation QuarantineViolation, comment string, expiry time.Time) (string, error) {
+	options := nxiq.ApiWaiverOptionsDTO{
+		Comment:         &comment,
+		MatcherStrategy: nxiq.PtrString("EXACT_COMPONENT"),
+		ExpiryTime:      &expiry,
+	}
+	apiResponse, err := c.apiClient.PolicyWaiversAPI.AddPolicyWaiverByPolicyViolationId(
+		*c.ctx, waiverOwnerType, repositoryId, violation.PolicyViolationId,
+	).ApiWaiverOptionsDTO(options).Execute()
+	if err != nil {
+		return "", fmt.Errorf("failed to waive %s violation: %w", violation.PolicyName, err)
+	}
+	if apiResponse.StatusCode != http.StatusOK && apiResponse.StatusCode != http.StatusNoContent {
+		return "", fmt.Errorf("failed to waive %s violation - status code: %d", violation.PolicyName, apiResponse.StatusCode)
+	}
+
+	// Creating a waiver does not return its ID, so find it among the repository's waivers
+	waivers, apiResponse, err := c.apiClient.PolicyWaiversAPI.GetPolicyWaivers(*c.ctx, waiverOwnerType, repositoryId).Execute()
+	if err != nil {
+		return "", fmt.Errorf("failed to list waivers for repository %s: %w", repositoryId, err)
+	}
+	if apiResponse.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to list waivers for repository %s - status code: %d", repositoryId, apiResponse.StatusCode)
+	}
+	for _, waiver := range waivers {
+		if waiver.GetPolicyViolationId() == violation.PolicyViolationId && waiver.GetPolicyWaiverId() != "" {
+			return waiver.GetPolicyWaiverId(), nil
+		}
+	}
+	return "", fmt.Errorf("waiver for %s violation was created, but could not be found", violation.PolicyName)
+}
+
+// DeleteWaiver deletes a waiver scoped to the repository
+func (c *NxiqConnection) DeleteWaiver(repositoryId, policyWaiverId string) error {
+	apiResponse, err := c.apiClient.PolicyWaiversAPI.DeletePolicyWaiver(*c.ctx, waiverOwnerType, repositoryId, policyWaiverId).Execute()
+	if err != nil {
+		return fmt.Errorf("failed to delete waiver %s: %w", policyWaiverId, err)
+	}
+	if apiResponse.StatusCode != http.StatusOK && apiResponse.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("failed to delete waiver %s - status code: %d", policyWaiverId, apiResponse.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nxrm
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	v3 "github.com/sonatype-nexus-community/nexus-repo-api-client-go/v3"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+)
+
+// componentCoordinates returns the group and name Sonatype Nexus Repository gives the component holding a
+// test package - Maven and scoped NPM package names include their group
+func componentCoordinates(format formats.PackageFormat, pkg formats.Package) (string, string) {
+	switch format.GetName() {
+	case "maven2":
+		if group, name, ok := strings.Cut(pkg.Name, "/"); ok {
+			return group, name
+		}
+	case "npm":
+		if scope, name, ok := strings.Cut(pkg.Name, "/"); ok && strings.HasPrefix(scope, "@") {
+			return strings.TrimPrefix(scope, "@"), name
+		}
+	}
+	return "", pkg.Name
+}
+
+// isComponent returns true if the component has exactly the group, name and version - ignoring the case of
+// the group and name, as search does
+func isComponent(component v3.ComponentXO, group, name, version string) bool {
+	return strings.EqualFold(component.GetName(), name) &&
+		strings.EqualFold(component.GetGroup(), group) &&
+		component.GetVersion() == version
+}
+
+// FindCachedComponents returns the components of the repository holding a test package
+func (c *NxrmConnection) FindCachedComponents(repoName string, format formats.PackageFormat, pkg formats.Package) ([]v3.ComponentXO, error) {
+	group, name := componentCoordinates(format, pkg)
+
+	var components []v3.ComponentXO
+	continuationToken := ""
+	for {
+		request := c.apiClient.SearchAPI.Search(*c.ctx).Repository(repoName).Name(name).Version(pkg.Version)
+		if group != "" {
+			request = request.Group(group)
+		}
+		if continuationToken != "" {
+			request = request.ContinuationToken(continuationToken)
+		}
+		page, apiResponse, err := request.Execute()
+		if err != nil {
+			return nil, fmt.Errorf("failed to search %s for %s: %w", repoName, format.FormatPackageName(pkg), err)
+		}
+		if apiResponse.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to search %s for %s - status code: %d", repoName, format.FormatPackageName(pkg), apiResponse.StatusCode)
+		}
+
+		// Search matches loosely, so only keep exact matches
+		for _, component := range page.Items {
+			if isComponent(component, group, name, pkg.Version) {
+				components = append(components, component)
+			}
+		}
+
+		continuationToken = page.GetContinuationToken()
+		if continuationToken == "" {
+			return components, nil
+		}
+	}
+}
+
+// FindCachedAsset returns the asset already in the repository at the path requested for a test package, or
+// nil if it has not been cached
+func (c *NxrmConnection) FindCachedAsset(repoName string, format formats.PackageFormat, pkg formats.Package) (*formats.CachedAsset, error) {
+	requestURL := format.ConstructURL(c.baseUrl, repoName, pkg)
+	path, err := url.PathUnescape(strings.TrimPrefix(requestURL, fmt.Sprintf("%s/repository/%s/", c.baseUrl, repoName)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid request path for %s: %w", format.FormatPackageName(pkg), err)
+	}
+	group, name := componentCoordinates(format, pkg)
+
+	continuationToken := ""
+	for {
+		request := c.apiClient.SearchAPI.SearchAssets(*c.ctx).Repository(repoName).Name(name).Version(pkg.Version)
+		if group != "" {
+			request = request.Group(group)
+		}
+		if continuationToken != "" {
+			request = request.ContinuationToken(continuationToken)
+		}
+		page, apiResponse, err := request.Execute()
+		if err != nil {
+			return nil, fmt.Errorf("failed to search %s for %s: %w", repoName, format.FormatPackageName(pkg), err)
+		}
+		if apiResponse.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to search %s for %s - status code: %d", repoName, format.FormatPackageName(pkg), apiResponse.StatusCode)
+		}
+
+		for _, asset := range page.Items {
+			if strings.TrimPrefix(asset.GetPath(), "/") == path {
+				return &formats.CachedAsset{
+					Path:           path,
+					BlobCreated:    asset.BlobCreated,
+					LastDownloaded: asset.LastDownloaded,
+				}, nil
+			}
+		}
+
+		continuationToken = page.GetContinuationToken()
+		if continuationToken == "" {
+			return nil, nil
+		}
+	}
+}
+
+// PurgeTestComponents deletes every cached component holding a test package of the format from the
+// repository, then invalidates its negative and metadata caches so that Repository Firewall evaluates each
+// package afresh. It returns the number of components deleted
+func (c *NxrmConnection) PurgeTestComponents(repoName string, format formats.PackageFormat) (int, error) {
+	deleted := 0
+	for _, pkg := range format.GetPackages() {
+		components, err := c.FindCachedComponents(repoName, format, pkg)
+		if err != nil {
+			return deleted, err
+		}
+		for _, component := range components {
+			apiResponse, err := c.apiClient.ComponentsAPI.DeleteComponent(*c.ctx, component.GetId()).Execute()
+			if err != nil {
+				return deleted, fmt.Errorf("failed to delete %s from %s: %w", format.FormatPackageName(pkg), repoName, err)
+			}
+			if apiResponse.StatusCode != http.StatusNoContent && apiResponse.StatusCode != http.StatusOK {
+				return deleted, fmt.Errorf("failed to delete %s from %s - status code: %d", format.FormatPackageName(pkg), repoName, apiResponse.StatusCode)
+			}
+			deleted++
+		}
+	}
+
+	apiResponse, err := c.apiClient.RepositoryManagementAPI.CreaterepositorynameInvalidateCacheRepository(*c.ctx, repoName).Execute()
+	if err != nil {
+		return deleted, fmt.Errorf("failed to invalidate the caches of %s: %w", repoName, err)
+	}
+	if apiResponse.StatusCode != http.StatusNoContent && apiResponse.StatusCode != http.StatusOK {
+		return deleted, fmt.Errorf("failed to invalidate the caches of %s - status code: %d", repoName, apiResponse.StatusCode)
+	}
+
+	return deleted, nil
+}
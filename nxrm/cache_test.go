@@ -0,0 +1,145 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nxrm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	v3 "github.com/sonatype-nexus-community/nexus-repo-api-client-go/v3"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+)
+
+func TestComponentCoordinates(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    formats.PackageFormat
+		pkg       string
+		wantGroup string
+		wantName  string
+	}{
+		{"maven group and artifact", formats.MavenFormat{}, "org.sonatype/maven-policy-demo", "org.sonatype", "maven-policy-demo"},
+		{"maven without group", formats.MavenFormat{}, "maven-policy-demo", "", "maven-policy-demo"},
+		{"npm scoped", formats.NPMFormat{}, "@sonatype/policy-demo", "sonatype", "policy-demo"},
+		{"npm unscoped", formats.NPMFormat{}, "bson", "", "bson"},
+		{"npm slash without scope", formats.NPMFormat{}, "sonatype/policy-demo", "", "sonatype/policy-demo"},
+		{"other formats keep the whole name", formats.DockerFormat{}, "sonatypecommunity/docker-policy-demo", "", "sonatypecommunity/docker-policy-demo"},
+		{"pypi", formats.PyPIFormat{}, "python-policy-demo", "", "python-policy-demo"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			group, name := componentCoordinates(test.format, formats.Package{Name: test.pkg})
+			if group != test.wantGroup || name != test.wantName {
+				t.Errorf("componentCoordinates() = %q, %q, want %q, %q", group, name, test.wantGroup, test.wantName)
+			}
+		})
+	}
+}
+
+func TestIsComponent(t *testing.T) {
+	tests := []struct {
+		name      string
+		component v3.ComponentXO
+		version   string
+		want      bool
+	}{
+		{"exact", component("sonatype", "policy-demo", "2.2.0"), "2.2.0", true},
+		{"name and group differ in case", component("Sonatype", "Policy-Demo", "2.2.0"), "2.2.0", true},
+		{"other version", component("sonatype", "policy-demo", "2.2.0-beta"), "2.2.0", false},
+		{"version differs in case", component("sonatype", "policy-demo", "2.2.0-RC"), "2.2.0-rc", false},
+		{"other name", component("sonatype", "policy-demo-extra", "2.2.0"), "2.2.0", false},
+		{"other group", component("other", "policy-demo", "2.2.0"), "2.2.0", false},
+		{"no group", component("", "policy-demo", "2.2.0"), "2.2.0", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isComponent(test.component, "sonatype", "policy-demo", test.version); got != test.want {
+				t.Errorf("isComponent() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFindCachedComponents(t *testing.T) {
+	pages := map[string]v3.PageComponentXO{
+		"": {
+			Items: []v3.ComponentXO{
+				withId(component("sonatype", "policy-demo", "2.2.0"), "exact"),
+				withId(component("sonatype", "policy-demo", "2.2.0-beta"), "prerelease"),
+			},
+			ContinuationToken: v3.PtrString("next"),
+		},
+		"next": {
+			Items: []v3.ComponentXO{
+				withId(component("other", "policy-demo", "2.2.0"), "other-scope"),
+				withId(component("Sonatype", "policy-demo", "2.2.0"), "case"),
+			},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/service/rest/v1/search" || query.Get("repository") != "npm-proxy" ||
+			query.Get("group") != "sonatype" || query.Get("name") != "policy-demo" || query.Get("version") != "2.2.0" {
+			t.Errorf("unexpected search %s", r.URL)
+		}
+		page, ok := pages[query.Get("continuationToken")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		data, _ := page.MarshalJSON()
+		fmt.Fprint(w, string(data))
+	}))
+	defer server.Close()
+
+	configuration := v3.NewConfiguration()
+	configuration.Servers = v3.ServerConfigurations{{URL: server.URL + "/service/rest"}}
+	ctx := context.Background()
+	c := &NxrmConnection{apiClient: v3.NewAPIClient(configuration), baseUrl: server.URL, ctx: &ctx}
+
+	components, err := c.FindCachedComponents("npm-proxy", formats.NPMFormat{}, formats.Package{Name: "@sonatype/policy-demo", Version: "2.2.0"})
+	if err != nil {
+		t.Fatalf("FindCachedComponents() error: %v", err)
+	}
+	var ids []string
+	for _, component := range components {
+		ids = append(ids, component.GetId())
+	}
+	if want := []string{"exact", "case"}; !slices.Equal(ids, want) {
+		t.Errorf("FindCachedComponents() = %v, want %v", ids, want)
+	}
+}
+
+// component returns a search result with the given coordinates
+func component(group, name, version string) v3.ComponentXO {
+	c := v3.ComponentXO{Name: v3.PtrString(name), Version: v3.PtrString(version)}
+	if group != "" {
+		c.Group = v3.PtrString(group)
+	}
+	return c
+}
+
+// withId sets the identifier of a search result
+func withId(c v3.ComponentXO, id string) v3.ComponentXO {
+	c.Id = v3.PtrString(id)
+	return c
+}
@@ -0,0 +1,121 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nxrm
+
+import (
+	"fmt"
+	"net/http"
+
+	v3 "github.com/sonatype-nexus-community/nexus-repo-api-client-go/v3"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+)
+
+const (
+	provisionedContentMaxAge  = 1440
+	provisionedMetadataMaxAge = 1440
+	provisionedNegativeTTL    = 1440
+)
+
+// ProvisionProxyRepository creates a Proxy Repository for the format pointing at its public registry, then
+// enables Repository Firewall audit and quarantine for it
+func (c *NxrmConnection) ProvisionProxyRepository(format formats.PackageFormat, repoName, blobStoreName string) error {
+	httpClient := *v3.NewHttpClientAttributes(true, false)
+	negativeCache := *v3.NewNegativeCacheAttributes(true, provisionedNegativeTTL)
+	proxy := *v3.NewProxyAttributes(provisionedContentMaxAge, provisionedMetadataMaxAge)
+	proxy.SetRemoteUrl(format.GetUpstreamURL())
+	storage := *v3.NewStorageAttributes(blobStoreName, true)
+
+	api := c.apiClient.RepositoryManagementAPI
+	var apiResponse *http.Response
+	var err error
+	switch format.GetName() {
+	case "cargo":
+		body := v3.NewCargoProxyRepositoryApiRequest(httpClient, repoName, negativeCache, true, proxy, storage)
+		apiResponse, err = api.CreateCargoProxyRepository(*c.ctx).Body(*body).Execute()
+	case "conda":
+		body := v3.NewCondaProxyRepositoryApiRequest(httpClient, repoName, negativeCache, true, proxy, storage)
+		apiResponse, err = api.CreateCondaProxyRepository(*c.ctx).Body(*body).Execute()
+	case "docker":
+		// Test packages are requested by path, not on a port or subdomain
+		docker := v3.NewDockerAttributes(false, false)
+		docker.SetPathEnabled(true)
+		dockerProxy := v3.NewDockerProxyAttributes()
+		dockerProxy.SetIndexType("HUB")
+		body := v3.NewDockerProxyRepositoryApiRequest(*docker, *dockerProxy, httpClient, repoName, negativeCache, true, proxy, storage)
+		apiResponse, err = api.CreateDockerProxyRepository(*c.ctx).Body(*body).Execute()
+	case "go":
+		body := v3.NewGolangProxyRepositoryApiRequest(httpClient, repoName, negativeCache, true, proxy, storage)
+		apiResponse, err = api.CreateGoProxyRepository(*c.ctx).Body(*body).Execute()
+	case "huggingface":
+		body := v3.NewHuggingFaceProxyRepositoryApiRequest(httpClient, repoName, negativeCache, true, proxy, storage)
+		apiResponse, err = api.CreateHuggingfaceProxyRepository(*c.ctx).Body(*body).Execute()
+	case "maven2":
+		maven := v3.NewMavenAttributes()
+		maven.SetVersionPolicy("RELEASE")
+		maven.SetLayoutPolicy("PERMISSIVE")
+		body := v3.NewMavenProxyRepositoryApiRequest(*v3.NewHttpClientAttributesWithPreemptiveAuth(true, false), *maven, repoName, negativeCache, true, proxy, storage)
+		apiResponse, err = api.CreateMavenProxyRepository(*c.ctx).Body(*body).Execute()
+	case "npm":
+		body := v3.NewNpmProxyRepositoryApiRequest(httpClient, repoName, negativeCache, true, proxy, storage)
+		apiResponse, err = api.CreateNpmProxyRepository(*c.ctx).Body(*body).Execute()
+	case "nuget":
+		nuget := v3.NewNugetAttributes()
+		nuget.SetNugetVersion("V3")
+		body := v3.NewNugetProxyRepositoryApiRequest(httpClient, repoName, negativeCache, *nuget, true, proxy, storage)
+		apiResponse, err = api.CreateNugetProxyRepository(*c.ctx).Body(*body).Execute()
+	case "pypi":
+		body := v3.NewPypiProxyRepositoryApiRequest(httpClient, repoName, negativeCache, true, proxy, storage)
+		apiResponse, err = api.CreatePypiProxyRepository(*c.ctx).Body(*body).Execute()
+	case "r":
+		body := v3.NewRProxyRepositoryApiRequest(httpClient, repoName, negativeCache, true, proxy, storage)
+		apiResponse, err = api.CreateRProxyRepository(*c.ctx).Body(*body).Execute()
+	default:
+		return fmt.Errorf("cannot provision a %s proxy repository", format.GetName())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create proxy repository '%s': %w", repoName, err)
+	}
+	if apiResponse.StatusCode != http.StatusCreated && apiResponse.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to create proxy repository '%s' - status code: %d", repoName, apiResponse.StatusCode)
+	}
+
+	audit := v3.IqAuditXo{RepositoryName: repoName, EnabledQuarantine: true}
+	audit.SetEnabled(true)
+	apiResponse, err = c.apiClient.ManageSonatypeRepositoryFirewallConfigurationAPI.ManageAudit(*c.ctx).Body(audit).Execute()
+	if err == nil && apiResponse.StatusCode != http.StatusNoContent && apiResponse.StatusCode != http.StatusOK {
+		err = fmt.Errorf("status code: %d", apiResponse.StatusCode)
+	}
+	if err != nil {
+		// A repository without quarantine is no use for testing - best effort, as the original error matters more
+		_ = c.DeleteRepository(repoName)
+		return fmt.Errorf("failed to enable Repository Firewall quarantine for '%s': %w", repoName, err)
+	}
+
+	return nil
+}
+
+// DeleteRepository deletes the named repository and everything it has cached
+func (c *NxrmConnection) DeleteRepository(repoName string) error {
+	apiResponse, err := c.apiClient.RepositoryManagementAPI.DeleteRepository(*c.ctx, repoName).Execute()
+	if err != nil {
+		return fmt.Errorf("failed to delete repository '%s': %w", repoName, err)
+	}
+	if apiResponse.StatusCode != http.StatusNoContent && apiResponse.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to delete repository '%s' - status code: %d", repoName, apiResponse.StatusCode)
+	}
+	return nil
+}
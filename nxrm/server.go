@@ -19,10 +19,10 @@ package nxrm
 import (
 	"context"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"time"
 
 	v3 "github.com/sonatype-nexus-community/nexus-repo-api-client-go/v3"
 	"github.com/sonatype-nexus-community/nxfw-policy-tester/cli"
@@ -63,7 +63,7 @@ func (c *NxrmConnection) CheckPackages(repoName string, format formats.PackageFo
 	// Parse the URL
 	repoDomainNameParts, err := url.Parse(c.baseUrl)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	// Get the domain (host)
@@ -81,11 +81,20 @@ func (c *NxrmConnection) CheckPackages(repoName string, format formats.PackageFo
 			util.ColorReset,
 		)
 
+		checkStarted := time.Now()
+
+		// Firewall does not evaluate packages the repository has already cached
+		cached, cacheErr := c.FindCachedAsset(repoName, format, pkg)
+		if cacheErr != nil {
+			cli.PrintCliln(fmt.Sprintf("Warning: Unable to tell whether the package was already cached: %v", cacheErr), util.ColorYellow)
+		}
+
 		url := format.ConstructURL(c.baseUrl, repoName, pkg)
 		httpCode, err := c.DownloadPackageAtUrl(url)
 
 		result := formats.CheckResult{
 			Package:                       pkg,
+			URL:                           url,
 			HTTPCode:                      httpCode,
 			Available:                     false,
 			Failed:                        false,
@@ -104,6 +113,17 @@ func (c *NxrmConnection) CheckPackages(repoName string, format formats.PackageFo
 				util.ColorRed,
 			)
 			result.Failed = true
+		} else if httpCode == http.StatusOK && cached != nil {
+			cli.PrintCliln(
+				fmt.Sprintf(
+					"~ Package available, but was already cached: %s [%s]",
+					format.FormatPackageName(pkg),
+					pkg.PolicyName,
+				),
+				util.ColorYellow,
+			)
+			result.Available = true
+			result.Cached = cached
 		} else if httpCode == http.StatusOK {
 			cli.PrintCliln(
 				fmt.Sprintf(
@@ -116,17 +136,20 @@ func (c *NxrmConnection) CheckPackages(repoName string, format formats.PackageFo
 			result.Available = true
 		} else if httpCode == http.StatusForbidden {
 			if nxiqConnection == nil {
-				println("ERROR: NO Connection to IQ")
-				os.Exit(1)
+				return nil, fmt.Errorf("no connection to Sonatype IQ Server to check the quarantine of %s", format.FormatPackageName(pkg))
 			}
-			quarantined, policyTriggered, fwErr := nxiqConnection.RetrieveFWQuarantineStatus(
+			fwStatus, fwErr := nxiqConnection.RetrieveFWQuarantineStatus(
 				pkg.Name, pkg.Version, repoName, string(pkg.PolicyName), format.GetName(), repoDomainName,
 			)
 			if fwErr != nil {
 				cli.PrintCliln(fmt.Sprintf("Error checking Firewall Quarantine Status: %v", fwErr), util.ColorRed)
 			}
-			result.Quarantined = quarantined
-			result.QuarantinedWithExpectedPolicy = policyTriggered
+			result.Quarantined = fwStatus.Quarantined
+			result.QuarantinedWithExpectedPolicy = fwStatus.ExpectedPolicyTriggered
+			result.QuarantinedByPolicies = fwStatus.Policies
+			result.MatchedPolicy = fwStatus.MatchedPolicy
+			result.QuarantinePageURL = fwStatus.QuarantinePageURL
+			result.QuarantineHash = fwStatus.Hash
 
 			cli.PrintCliln(
 				fmt.Sprintf(
@@ -149,6 +172,7 @@ func (c *NxrmConnection) CheckPackages(repoName string, format formats.PackageFo
 			result.Available = false
 		}
 
+		result.Duration = time.Since(checkStarted)
 		results = append(results, result)
 	}
 
@@ -177,6 +201,11 @@ func (c *NxrmConnection) DownloadPackageAtUrl(url string) (int, error) {
 	return resp.StatusCode, nil
 }
 
+// GetBaseUrl returns the URL of Sonatype Nexus Repository
+func (c *NxrmConnection) GetBaseUrl() string {
+	return c.baseUrl
+}
+
 func (c *NxrmConnection) GetConnectedIqServer() (string, error) {
 	iqConnection, apiResponse, err := c.apiClient.ManageSonatypeRepositoryFirewallConfigurationAPI.GetConfiguration(*c.ctx).Execute()
 
@@ -189,12 +218,12 @@ func (c *NxrmConnection) GetConnectedIqServer() (string, error) {
 	return *iqConnection.Url, nil
 }
 
-// SelectRepository prompts the user to select a repository from available proxies in given format
-func (c *NxrmConnection) SelectRepository(formatName string) (string, error) {
+// GetProxyRepositories returns all proxy repositories in the given format
+func (c *NxrmConnection) GetProxyRepositories(formatName string) ([]v3.RepositoryXO, error) {
 	// Get all repositories using the RepositoryManagementAPI
 	repos, _, err := c.apiClient.RepositoryManagementAPI.GetAllRepositories(*c.ctx).Execute()
 	if err != nil {
-		return "", fmt.Errorf("failed to list repositories: %w", err)
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
 	}
 
 	// Filter for proxy repositories of the correct format
@@ -206,13 +235,81 @@ func (c *NxrmConnection) SelectRepository(formatName string) (string, error) {
 		}
 	}
 
+	return proxyRepos, nil
+}
+
+// GetFirewallStatuses returns whether Sonatype Repository Firewall audits and quarantines each repository,
+// keyed by repository name. Repositories without Firewall configuration are neither audited nor quarantined
+func (c *NxrmConnection) GetFirewallStatuses() (map[string]formats.FirewallStatus, error) {
+	audits, apiResponse, err := c.apiClient.ManageSonatypeRepositoryFirewallConfigurationAPI.GetAllAuditStatus(*c.ctx).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Repository Firewall audit status: %w", err)
+	}
+	if apiResponse.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to read Repository Firewall audit status - status code: %d", apiResponse.StatusCode)
+	}
+
+	statuses := make(map[string]formats.FirewallStatus, len(audits))
+	for _, audit := range audits {
+		statuses[audit.RepositoryName] = formats.FirewallStatus{
+			Known:             true,
+			AuditEnabled:      audit.GetEnabled(),
+			QuarantineEnabled: audit.EnabledQuarantine,
+		}
+	}
+	return statuses, nil
+}
+
+// FirewallStatusOf looks up a repository in statuses read with GetFirewallStatuses - a nil map means they
+// could not be read
+func FirewallStatusOf(statuses map[string]formats.FirewallStatus, repoName string) formats.FirewallStatus {
+	if statuses == nil {
+		return formats.FirewallStatus{}
+	}
+	if status, ok := statuses[repoName]; ok {
+		return status
+	}
+	return formats.FirewallStatus{Known: true}
+}
+
+// ValidateRepository confirms the named repository exists and is a proxy in the given format
+func (c *NxrmConnection) ValidateRepository(formatName, repoName string) error {
+	proxyRepos, err := c.GetProxyRepositories(formatName)
+	if err != nil {
+		return err
+	}
+
+	for _, repo := range proxyRepos {
+		if repo.GetName() == repoName {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("repository '%s' is not a %s proxy repository", repoName, formatName)
+}
+
+// SelectRepository prompts the user to select a repository from available proxies in given format
+func (c *NxrmConnection) SelectRepository(formatName string) (string, error) {
+	proxyRepos, err := c.GetProxyRepositories(formatName)
+	if err != nil {
+		return "", err
+	}
+
 	if len(proxyRepos) == 0 {
 		return "", fmt.Errorf("no %s proxy repositories found", formatName)
 	}
 
+	// The selector still works if Firewall status cannot be read
+	statuses, _ := c.GetFirewallStatuses()
+
 	cli.PrintCliln(fmt.Sprintf("\nAvailable %s proxy repositories:", formatName), util.ColorYellow)
 	for i, repo := range proxyRepos {
-		cli.PrintCliln(fmt.Sprintf("%2d) %s", i+1, repo.GetName()), util.ColorReset)
+		status := FirewallStatusOf(statuses, repo.GetName())
+		color := util.ColorGreen
+		if !status.Known || status.QuarantineDisabled() {
+			color = util.ColorYellow
+		}
+		cli.PrintCliln(fmt.Sprintf("%2d) %s %s[%s]", i+1, repo.GetName(), color, status), util.ColorReset)
 	}
 
 	choice := cli.ReadInput("\nSelect repository (enter number): ")
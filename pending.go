@@ -0,0 +1,95 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/cli"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/nxiq"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/util"
+)
+
+// maxTrackPendingInterval caps the backoff between checks of pending packages
+const maxTrackPendingInterval = 5 * time.Minute
+
+// trackPending checks Sonatype IQ Server, backing off between checks, until every quarantined pending test
+// package has been released or confirmed quarantined, or the timeout passes. The timeline of each is recorded
+// in its result
+func trackPending(nxiqConnection *nxiq.NxiqConnection, repoName string, format formats.PackageFormat, results []formats.CheckResult, interval, timeout time.Duration) error {
+	var tracked []int
+	for i := range results {
+		if results[i].Package.Integrity == formats.IntegrityPending && results[i].Quarantined && results[i].QuarantineHash != "" {
+			results[i].IntegrityTimeline = &formats.IntegrityTimeline{}
+			tracked = append(tracked, i)
+		}
+	}
+	if len(tracked) == 0 {
+		return nil
+	}
+
+	cli.PrintCliln(fmt.Sprintf("\nTracking %d pending package(s) until released or confirmed quarantined (up to %s)...", len(tracked), timeout), util.ColorYellow)
+	deadline := time.Now().Add(timeout)
+	for {
+		quarantined, err := nxiqConnection.GetQuarantinedComponents(repoName)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		pending := 0
+		for _, i := range tracked {
+			timeline := results[i].IntegrityTimeline
+			if timeline.Resolved {
+				continue
+			}
+			state := formats.IntegrityReleased
+			if component, ok := quarantined[results[i].QuarantineHash]; ok {
+				if timeline.QuarantinedAt == nil {
+					timeline.QuarantinedAt = component.QuarantineTime
+				}
+				state = nxiqConnection.IntegrityStateOf(component)
+			}
+			timeline.Observe(now, state)
+			if !timeline.Resolved {
+				pending++
+				continue
+			}
+			cli.PrintCliln(
+				fmt.Sprintf("✓ %s %s after %s", format.FormatPackageName(results[i].Package), state, timeline.BlockedFor().Round(time.Second)),
+				util.ColorGreen,
+			)
+		}
+
+		if pending == 0 {
+			return nil
+		}
+		if now.Add(interval).After(deadline) {
+			cli.PrintCliln(fmt.Sprintf("Warning: %d package(s) were still pending after %s", pending, timeout), util.ColorYellow)
+			return nil
+		}
+		time.Sleep(interval)
+		interval = nextTrackPendingInterval(interval)
+	}
+}
+
+// nextTrackPendingInterval doubles the interval between checks of pending packages, up to the maximum
+func nextTrackPendingInterval(interval time.Duration) time.Duration {
+	return min(interval*2, maxTrackPendingInterval)
+}
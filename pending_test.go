@@ -0,0 +1,40 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextTrackPendingInterval(t *testing.T) {
+	tests := []struct {
+		interval time.Duration
+		want     time.Duration
+	}{
+		{15 * time.Second, 30 * time.Second},
+		{2 * time.Minute, 4 * time.Minute},
+		{4 * time.Minute, maxTrackPendingInterval},
+		{maxTrackPendingInterval, maxTrackPendingInterval},
+		{time.Hour, maxTrackPendingInterval},
+	}
+	for _, test := range tests {
+		if got := nextTrackPendingInterval(test.interval); got != test.want {
+			t.Errorf("nextTrackPendingInterval(%s) = %s, want %s", test.interval, got, test.want)
+		}
+	}
+}
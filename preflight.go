@@ -0,0 +1,79 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/cli"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/nxiq"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/util"
+)
+
+// discoverPolicies lists the Sonatype IQ Server policies that apply to Proxy Repositories and works out the
+// expected action for each Reference Policy before any packages are downloaded
+func discoverPolicies(nxiqConnection *nxiq.NxiqConnection) map[formats.PolicyName]formats.ExpectedAction {
+	policies, err := nxiqConnection.GetRepositoryPolicies()
+	if err != nil {
+		cli.PrintCliln(fmt.Sprintf("Error: %v", err), util.ColorRed)
+		exit(exitRunError)
+	}
+	slices.SortStableFunc(policies, func(a, b nxiq.Policy) int {
+		if a.ThreatLevel != b.ThreatLevel {
+			return int(b.ThreatLevel - a.ThreatLevel)
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	cli.PrintCliln("\n=== Sonatype IQ Server Policies ===\n", util.ColorYellow)
+	cli.PrintCliln(fmt.Sprintf("  %-6s  %-12s  %s", "Threat", "Proxy Action", "Policy"), util.ColorReset)
+	for _, policy := range policies {
+		action := string(policy.ProxyAction)
+		color := util.ColorReset
+		switch policy.ProxyAction {
+		case nxiq.ProxyActionFail:
+			color = util.ColorCyan
+		case nxiq.ProxyActionUnknown:
+			action = "unknown"
+			color = util.ColorYellow
+		}
+		cli.PrintCliln(fmt.Sprintf("  %-6d  %s%-12s%s  %s", policy.ThreatLevel, color, action, util.ColorReset, policy.Name), util.ColorReset)
+	}
+
+	expected := nxiqConnection.ExpectedActions(policies)
+
+	cli.PrintCliln("\n=== Reference Policy Expectations ===\n", util.ColorYellow)
+	for _, reference := range formats.AllPolicyNames {
+		action, ok := expected[reference]
+		if !ok {
+			continue
+		}
+		switch action {
+		case formats.ExpectQuarantine:
+			cli.PrintCliln(fmt.Sprintf("  %-32s should quarantine", reference), util.ColorGreen)
+		case formats.ExpectWarnOnly:
+			cli.PrintCliln(fmt.Sprintf("  %-32s warn only", reference), util.ColorYellow)
+		default:
+			cli.PrintCliln(fmt.Sprintf("  %-32s no matching policy", reference), util.ColorRed)
+		}
+	}
+
+	return expected
+}
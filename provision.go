@@ -0,0 +1,73 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/cli"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/nxrm"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/util"
+)
+
+// provisionedRepositoryPrefix starts the name of every Proxy Repository created by --provision
+const provisionedRepositoryPrefix = "nxfw-policy-test"
+
+// provisionedRepositoryKey matches repositories created by --provision by format alone, as each is named with
+// the time it was created - see report.Compare
+func provisionedRepositoryKey(format, repository string) string {
+	provisioned := fmt.Sprintf("%s-%s", provisionedRepositoryPrefix, format)
+	if strings.HasPrefix(repository, provisioned+"-") {
+		return provisioned
+	}
+	return repository
+}
+
+// provisionTargets creates a temporary Proxy Repository with Repository Firewall quarantine enabled for each
+// format. Unless kept, each is deleted when the tool exits - including on failure or Ctrl-C
+func provisionTargets(selectedFormats []formats.PackageFormat, blobStoreName string, keep bool, nxrmConnection *nxrm.NxrmConnection) []runTarget {
+	handleInterrupts()
+
+	suffix := time.Now().Format("20060102-150405")
+	var targets []runTarget
+	for _, format := range selectedFormats {
+		repoName := fmt.Sprintf("%s-%s-%s", provisionedRepositoryPrefix, format.GetName(), suffix)
+		if err := nxrmConnection.ProvisionProxyRepository(format, repoName, blobStoreName); err != nil {
+			cli.PrintCliln(fmt.Sprintf("Error: %v", err), util.ColorRed)
+			exit(exitRunError)
+		}
+		cli.PrintCliln(fmt.Sprintf("✓ Provisioned %s proxy repository %s of %s", format.GetDisplayName(), repoName, format.GetUpstreamURL()), util.ColorGreen)
+
+		if keep {
+			cli.PrintCliln(fmt.Sprintf("  %s will be kept once the run completes", repoName), util.ColorReset)
+		} else {
+			onExit(func() {
+				if err := nxrmConnection.DeleteRepository(repoName); err != nil {
+					cli.PrintCliln(fmt.Sprintf("Warning: %v - please delete it manually", err), util.ColorYellow)
+					return
+				}
+				cli.PrintCliln(fmt.Sprintf("✓ Deleted provisioned repository %s", repoName), util.ColorGreen)
+			})
+		}
+
+		targets = append(targets, runTarget{format: format, repoName: repoName})
+	}
+	return targets
+}
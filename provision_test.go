@@ -0,0 +1,37 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import "testing"
+
+func TestProvisionedRepositoryKey(t *testing.T) {
+	tests := []struct {
+		format     string
+		repository string
+		want       string
+	}{
+		{"npm", "nxfw-policy-test-npm-20260101-090000", "nxfw-policy-test-npm"},
+		{"npm", "nxfw-policy-test-npm-20260201-090000", "nxfw-policy-test-npm"},
+		{"npm", "nxfw-policy-test-pypi-20260101-090000", "nxfw-policy-test-pypi-20260101-090000"},
+		{"npm", "npm-proxy", "npm-proxy"},
+	}
+	for _, test := range tests {
+		if got := provisionedRepositoryKey(test.format, test.repository); got != test.want {
+			t.Errorf("provisionedRepositoryKey(%s, %s) = %s, want %s", test.format, test.repository, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report
+
+import (
+	"slices"
+	"time"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+)
+
+// ChangeKind describes how a result differs from the baseline
+type ChangeKind string
+
+const (
+	// ChangeNewlyAvailable - the package was not downloadable in the baseline, but now is (protection lost)
+	ChangeNewlyAvailable ChangeKind = "newly-available"
+	// ChangeNewlyQuarantined - the package was not quarantined in the baseline, but now is
+	ChangeNewlyQuarantined ChangeKind = "newly-quarantined"
+	// ChangePolicyChanged - the package is still quarantined, but by different policies
+	ChangePolicyChanged ChangeKind = "policy-changed"
+	// ChangeStatusChanged - any other change of status (e.g. a download that now fails)
+	ChangeStatusChanged ChangeKind = "status-changed"
+	// ChangeDisappeared - the package was tested in the baseline, but is no longer in the catalog
+	ChangeDisappeared ChangeKind = "disappeared"
+	// ChangeAdded - the package is new to the catalog since the baseline
+	ChangeAdded ChangeKind = "added"
+)
+
+// Comparison lists the differences between a run and an earlier baseline run
+type Comparison struct {
+	BaselineStartedAt time.Time `json:"baselineStartedAt"`
+	Changes           []Change  `json:"changes"`
+}
+
+// Change is a single difference from the baseline
+type Change struct {
+	Kind             ChangeKind           `json:"kind"`
+	Format           string               `json:"format"`
+	Repository       string               `json:"repository"`
+	Package          string               `json:"package"`
+	ExpectedPolicy   formats.PolicyName   `json:"expectedPolicy"`
+	PreviousStatus   formats.ResultStatus `json:"previousStatus,omitempty"`
+	CurrentStatus    formats.ResultStatus `json:"currentStatus,omitempty"`
+	PreviousPolicies []string             `json:"previousPolicies,omitempty"`
+	CurrentPolicies  []string             `json:"currentPolicies,omitempty"`
+}
+
+// baselineKey identifies the same check across runs
+type baselineKey struct {
+	format     string
+	repository string
+	name       string
+	version    string
+	policy     formats.PolicyName
+}
+
+// RepositoryKey returns the name a repository is matched by across runs - e.g. so that repositories named
+// differently by each run can be compared
+type RepositoryKey func(format, repository string) string
+
+type runKey struct {
+	format     string
+	repository string
+}
+
+// Compare returns the differences between current and baseline. Only formats and repositories tested in
+// both runs are compared, so that testing a subset of the baseline does not report every other package as
+// having disappeared. repositoryKey, if not nil, gives the name each repository is matched by
+func Compare(baseline, current *Report, repositoryKey RepositoryKey) *Comparison {
+	if repositoryKey == nil {
+		repositoryKey = func(_, repository string) string { return repository }
+	}
+	keyOf := func(run Run) runKey {
+		return runKey{format: run.Format, repository: repositoryKey(run.Format, run.Repository)}
+	}
+	resultKey := func(run Run, result Result) baselineKey {
+		return baselineKey{
+			format:     run.Format,
+			repository: keyOf(run).repository,
+			name:       result.Package.Name,
+			version:    result.Package.Version,
+			policy:     result.ExpectedPolicy,
+		}
+	}
+
+	comparison := &Comparison{
+		BaselineStartedAt: baseline.StartedAt,
+		Changes:           []Change{},
+	}
+
+	currentRuns := make(map[runKey]bool)
+	for _, run := range current.Runs {
+		currentRuns[keyOf(run)] = true
+	}
+
+	previous := make(map[baselineKey]Result)
+	baselineRuns := make(map[runKey]bool)
+	for _, run := range baseline.Runs {
+		if !currentRuns[keyOf(run)] {
+			continue
+		}
+		baselineRuns[keyOf(run)] = true
+		for _, result := range run.Results {
+			previous[resultKey(run, result)] = result
+		}
+	}
+
+	for _, run := range current.Runs {
+		if !baselineRuns[keyOf(run)] {
+			continue
+		}
+		for _, result := range run.Results {
+			key := resultKey(run, result)
+			was, ok := previous[key]
+			delete(previous, key)
+
+			change := Change{
+				Format:          run.Format,
+				Repository:      run.Repository,
+				Package:         result.Package.DisplayName,
+				ExpectedPolicy:  result.ExpectedPolicy,
+				CurrentStatus:   result.Status,
+				CurrentPolicies: result.QuarantinedByPolicies,
+			}
+			if !ok {
+				change.Kind = ChangeAdded
+				comparison.Changes = append(comparison.Changes, change)
+				continue
+			}
+			change.PreviousStatus = was.Status
+			change.PreviousPolicies = was.QuarantinedByPolicies
+
+			wasQuarantined := isQuarantined(was.Status)
+			nowQuarantined := isQuarantined(result.Status)
+			switch {
+			case result.Status == formats.StatusAvailable && was.Status != formats.StatusAvailable && was.Status != formats.StatusCached &&
+				expectedAction(result).BlocksDownload():
+				// Only a package Firewall should block can lose protection
+				change.Kind = ChangeNewlyAvailable
+			case nowQuarantined && !wasQuarantined:
+				change.Kind = ChangeNewlyQuarantined
+			case nowQuarantined && wasQuarantined && !samePolicies(was.QuarantinedByPolicies, result.QuarantinedByPolicies):
+				change.Kind = ChangePolicyChanged
+			case result.Status != was.Status:
+				change.Kind = ChangeStatusChanged
+			default:
+				continue
+			}
+			comparison.Changes = append(comparison.Changes, change)
+		}
+	}
+
+	// Anything left in the baseline was not tested this time
+	for _, run := range baseline.Runs {
+		for _, result := range run.Results {
+			if _, ok := previous[resultKey(run, result)]; !ok {
+				continue
+			}
+			comparison.Changes = append(comparison.Changes, Change{
+				Kind:             ChangeDisappeared,
+				Format:           run.Format,
+				Repository:       run.Repository,
+				Package:          result.Package.DisplayName,
+				ExpectedPolicy:   result.ExpectedPolicy,
+				PreviousStatus:   result.Status,
+				PreviousPolicies: result.QuarantinedByPolicies,
+			})
+		}
+	}
+
+	return comparison
+}
+
+// Count returns the number of changes of the given kind
+func (c *Comparison) Count(kind ChangeKind) int {
+	count := 0
+	for _, change := range c.Changes {
+		if change.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+// HasRegressions returns true if Firewall protection was lost for any package since the baseline
+func (c *Comparison) HasRegressions() bool {
+	return c.Count(ChangeNewlyAvailable) > 0
+}
+
+func isQuarantined(status formats.ResultStatus) bool {
+	return status == formats.StatusQuarantined || status == formats.StatusQuarantinedOtherPolicy
+}
+
+// samePolicies compares two lists of policy names, ignoring order
+func samePolicies(a, b []string) bool {
+	return slices.Equal(slices.Sorted(slices.Values(a)), slices.Sorted(slices.Values(b)))
+}
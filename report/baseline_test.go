@@ -0,0 +1,154 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+)
+
+// result returns a result for the named package
+func result(name string, status formats.ResultStatus, policies ...string) Result {
+	return Result{
+		Package:               PackageInfo{Name: name, Version: "1.0.0", DisplayName: name + "@1.0.0"},
+		ExpectedPolicy:        formats.SecurityCritical,
+		ExpectedAction:        formats.ExpectQuarantine,
+		Status:                status,
+		QuarantinedByPolicies: policies,
+	}
+}
+
+// allowed returns a result for the named package, expected to be downloadable
+func allowed(name string, status formats.ResultStatus) Result {
+	return withAction(result(name, status), formats.ExpectAllow)
+}
+
+// withAction sets the expected action of a result
+func withAction(r Result, action formats.ExpectedAction) Result {
+	r.ExpectedAction = action
+	return r
+}
+
+// reportOf returns a report with a single run
+func reportOf(format, repository string, results ...Result) *Report {
+	return &Report{Runs: []Run{{Format: format, Repository: repository, Results: results}}}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name          string
+		baseline      *Report
+		current       *Report
+		repositoryKey RepositoryKey
+		want          []ChangeKind
+	}{
+		{
+			name:     "unchanged",
+			baseline: reportOf("npm", "npm-proxy", result("a", formats.StatusQuarantined, "Security-Critical")),
+			current:  reportOf("npm", "npm-proxy", result("a", formats.StatusQuarantined, "Security-Critical")),
+		},
+		{
+			name:     "newly available",
+			baseline: reportOf("npm", "npm-proxy", result("a", formats.StatusQuarantined)),
+			current:  reportOf("npm", "npm-proxy", result("a", formats.StatusAvailable)),
+			want:     []ChangeKind{ChangeNewlyAvailable},
+		},
+		{
+			name:     "cached then available",
+			baseline: reportOf("npm", "npm-proxy", result("a", formats.StatusCached)),
+			current:  reportOf("npm", "npm-proxy", result("a", formats.StatusAvailable)),
+			want:     []ChangeKind{ChangeStatusChanged},
+		},
+		{
+			name:     "newly quarantined",
+			baseline: reportOf("npm", "npm-proxy", result("a", formats.StatusAvailable)),
+			current:  reportOf("npm", "npm-proxy", result("a", formats.StatusQuarantined)),
+			want:     []ChangeKind{ChangeNewlyQuarantined},
+		},
+		{
+			name:     "policy changed",
+			baseline: reportOf("npm", "npm-proxy", result("a", formats.StatusQuarantined, "Security-Critical", "Security-High")),
+			current:  reportOf("npm", "npm-proxy", result("a", formats.StatusQuarantined, "Security-High")),
+			want:     []ChangeKind{ChangePolicyChanged},
+		},
+		{
+			name:     "policies reordered",
+			baseline: reportOf("npm", "npm-proxy", result("a", formats.StatusQuarantined, "Security-Critical", "Security-High")),
+			current:  reportOf("npm", "npm-proxy", result("a", formats.StatusQuarantined, "Security-High", "Security-Critical")),
+		},
+		{
+			name:     "status changed",
+			baseline: reportOf("npm", "npm-proxy", result("a", formats.StatusQuarantined)),
+			current:  reportOf("npm", "npm-proxy", result("a", formats.StatusFailed)),
+			want:     []ChangeKind{ChangeStatusChanged},
+		},
+		{
+			name:     "added and disappeared",
+			baseline: reportOf("npm", "npm-proxy", result("a", formats.StatusQuarantined)),
+			current:  reportOf("npm", "npm-proxy", result("b", formats.StatusQuarantined)),
+			want:     []ChangeKind{ChangeAdded, ChangeDisappeared},
+		},
+		{
+			name:     "other repository not compared",
+			baseline: reportOf("npm", "npm-proxy", result("a", formats.StatusQuarantined)),
+			current:  reportOf("npm", "npm-proxy-eu", result("a", formats.StatusAvailable)),
+		},
+		{
+			name:     "allowed package newly available",
+			baseline: reportOf("npm", "npm-proxy", allowed("a", formats.StatusFailed)),
+			current:  reportOf("npm", "npm-proxy", allowed("a", formats.StatusAvailable)),
+			want:     []ChangeKind{ChangeStatusChanged},
+		},
+		{
+			name:     "allowed package newly available before 1.4",
+			baseline: reportOf("npm", "npm-proxy", Result{Package: PackageInfo{Name: "a", Version: "1.0.0"}, ExpectedPolicy: formats.None, Status: formats.StatusUnknown}),
+			current:  reportOf("npm", "npm-proxy", Result{Package: PackageInfo{Name: "a", Version: "1.0.0"}, ExpectedPolicy: formats.None, Status: formats.StatusAvailable}),
+			want:     []ChangeKind{ChangeStatusChanged},
+		},
+		{
+			name:     "warn only package newly available",
+			baseline: reportOf("npm", "npm-proxy", withAction(result("a", formats.StatusQuarantined), formats.ExpectWarnOnly)),
+			current:  reportOf("npm", "npm-proxy", withAction(result("a", formats.StatusAvailable), formats.ExpectWarnOnly)),
+			want:     []ChangeKind{ChangeStatusChanged},
+		},
+		{
+			name:          "repositories matched by key",
+			baseline:      reportOf("npm", "npm-proxy-20260101", result("a", formats.StatusQuarantined)),
+			current:       reportOf("npm", "npm-proxy-20260201", result("a", formats.StatusAvailable)),
+			repositoryKey: func(_, repository string) string { return strings.Split(repository, "-2026")[0] },
+			want:          []ChangeKind{ChangeNewlyAvailable},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			comparison := Compare(test.baseline, test.current, test.repositoryKey)
+			var got []ChangeKind
+			for _, change := range comparison.Changes {
+				got = append(got, change.Kind)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("Compare() changes = %v, want %v", got, test.want)
+			}
+			if regressed := slices.Contains(test.want, ChangeNewlyAvailable); comparison.HasRegressions() != regressed {
+				t.Errorf("HasRegressions() = %v, want %v", comparison.HasRegressions(), regressed)
+			}
+		})
+	}
+}
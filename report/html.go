@@ -0,0 +1,118 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+)
+
+//go:embed templates/report.html
+var htmlTemplate string
+
+// HTMLReporter writes a Report as a single self-contained HTML page, suitable for sharing with auditors
+type HTMLReporter struct{}
+
+type htmlPolicyRow struct {
+	Policy      formats.PolicyName
+	ThreatClass formats.ThreatClass
+	Counts      Counts
+	Outcomes    map[string]int
+}
+
+type htmlRow struct {
+	Run    Run
+	Result Result
+}
+
+type htmlData struct {
+	Report    *Report
+	Totals    Counts
+	Outcomes  map[string]int
+	Policies  []htmlPolicyRow
+	Rows      []htmlRow
+	Timelines []htmlRow
+}
+
+func (h HTMLReporter) GetName() string {
+	return "html"
+}
+
+func (h HTMLReporter) Write(w io.Writer, r *Report) error {
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"threatClass": func(policy formats.PolicyName) formats.ThreatClass {
+			return policy.GetThreatClass()
+		},
+		"timestamp": func(t time.Time) string {
+			return t.UTC().Format(time.RFC3339)
+		},
+		"duration": func(start, end time.Time) string {
+			return end.Sub(start).Round(time.Second).String()
+		},
+		"milliseconds": func(ms int64) string {
+			return (time.Duration(ms) * time.Millisecond).Round(time.Second).String()
+		},
+	}).Parse(htmlTemplate)
+	if err != nil {
+		return err
+	}
+
+	data := htmlData{
+		Report:   r,
+		Outcomes: map[string]int{},
+	}
+
+	policyIndex := make(map[formats.PolicyName]int)
+	for _, policy := range formats.AllPolicyNames {
+		policyIndex[policy] = len(data.Policies)
+		data.Policies = append(data.Policies, htmlPolicyRow{
+			Policy:      policy,
+			ThreatClass: policy.GetThreatClass(),
+			Outcomes:    map[string]int{},
+		})
+	}
+
+	for _, run := range r.Runs {
+		for _, result := range run.Results {
+			data.Totals.add(result.Status)
+			data.Outcomes[string(result.Outcome)]++
+			if i, ok := policyIndex[result.ExpectedPolicy]; ok {
+				data.Policies[i].Counts.add(result.Status)
+				data.Policies[i].Outcomes[string(result.Outcome)]++
+			}
+			data.Rows = append(data.Rows, htmlRow{Run: run, Result: result})
+			if result.IntegrityTimeline != nil {
+				data.Timelines = append(data.Timelines, htmlRow{Run: run, Result: result})
+			}
+		}
+	}
+
+	// Only list policies that at least one tested package was expected to trigger
+	policies := data.Policies[:0]
+	for _, row := range data.Policies {
+		if row.Counts.Total > 0 {
+			policies = append(policies, row)
+		}
+	}
+	data.Policies = policies
+
+	return tmpl.Execute(w, data)
+}
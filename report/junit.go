@@ -0,0 +1,184 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// JUnitReporter writes a Report as JUnit XML - one testsuite per format and repository, one testcase per package
+type JUnitReporter struct{}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Details string `xml:",chardata"`
+}
+
+func (j JUnitReporter) GetName() string {
+	return "junit"
+}
+
+func (j JUnitReporter) Write(w io.Writer, r *Report) error {
+	suites := junitTestSuites{Name: r.Tool.Name}
+
+	// Times are the sum of the test cases, as test report viewers expect
+	var totalDuration time.Duration
+	for _, run := range r.Runs {
+		var suiteDuration time.Duration
+		suite := junitTestSuite{
+			Name:      fmt.Sprintf("%s / %s", run.FormatDisplayName, run.Repository),
+			Timestamp: run.StartedAt.UTC().Format("2006-01-02T15:04:05"),
+			Properties: []junitProperty{
+				{Name: "nxrmUrl", Value: r.NxrmURL},
+				{Name: "iqUrl", Value: r.IqURL},
+				{Name: "format", Value: run.Format},
+				{Name: "repository", Value: run.Repository},
+				{Name: "toolVersion", Value: fmt.Sprintf("%s (%s)", r.Tool.Version, r.Tool.Commit)},
+			},
+		}
+
+		for _, result := range run.Results {
+			duration := time.Duration(result.DurationMs) * time.Millisecond
+			suiteDuration += duration
+			testCase := junitTestCase{
+				Name:      fmt.Sprintf("%s [%s]", result.Package.DisplayName, result.ExpectedPolicy),
+				ClassName: fmt.Sprintf("%s.%s", run.Format, run.Repository),
+				Time:      junitSeconds(duration),
+			}
+
+			switch result.Outcome {
+			case OutcomeGap:
+				testCase.Failure = &junitMessage{
+					Message: fmt.Sprintf("Expected %s to quarantine the package, but it was downloadable", result.ExpectedPolicy),
+					Type:    "PolicyGap",
+					Details: junitDetails(result),
+				}
+			case OutcomeMismatch:
+				message := fmt.Sprintf("Expected %s to quarantine the package, but it was quarantined by: %s", result.ExpectedPolicy, junitPolicies(result))
+				if !result.ExpectedAction.BlocksDownload() {
+					message = fmt.Sprintf("Expected the package to be downloadable (%s), but it was quarantined by: %s", result.ExpectedAction, junitPolicies(result))
+				}
+				testCase.Failure = &junitMessage{
+					Message: message,
+					Type:    "PolicyMismatch",
+					Details: junitDetails(result),
+				}
+			case OutcomeInconclusive:
+				testCase.Error = &junitMessage{
+					Message: fmt.Sprintf("Unable to determine the outcome - status %s, HTTP %d", result.Status, result.HTTPCode),
+					Type:    string(result.Status),
+					Details: junitDetails(result),
+				}
+			}
+
+			switch {
+			case testCase.Failure != nil:
+				suite.Failures++
+			case testCase.Error != nil:
+				suite.Errors++
+			default:
+				testCase.SystemOut = junitDetails(result)
+			}
+			suite.Tests++
+			suite.TestCases = append(suite.TestCases, testCase)
+		}
+
+		suite.Time = junitSeconds(suiteDuration)
+		totalDuration += suiteDuration
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Suites = append(suites.Suites, suite)
+	}
+	suites.Time = junitSeconds(totalDuration)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitSeconds formats a duration as JUnit expects
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// junitPolicies lists the policies that quarantined the package
+func junitPolicies(result Result) string {
+	if len(result.QuarantinedByPolicies) == 0 {
+		return "none"
+	}
+	return strings.Join(result.QuarantinedByPolicies, ", ")
+}
+
+// junitDetails describes a result for the body of a testcase
+func junitDetails(result Result) string {
+	return fmt.Sprintf(
+		"URL: %s\nHTTP Status: %d\nStatus: %s\nExpected Policy: %s\nQuarantined By: %s",
+		result.URL,
+		result.HTTPCode,
+		result.Status,
+		result.ExpectedPolicy,
+		junitPolicies(result),
+	)
+}
@@ -0,0 +1,71 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+)
+
+func TestJUnitTimes(t *testing.T) {
+	started := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	r := &Report{
+		StartedAt:  started,
+		FinishedAt: started.Add(time.Hour),
+		Runs: []Run{
+			{
+				Format: "npm", Repository: "npm-proxy", StartedAt: started, FinishedAt: started.Add(time.Minute),
+				Results: []Result{
+					{Package: PackageInfo{DisplayName: "a@1.0.0"}, ExpectedPolicy: formats.SecurityCritical, DurationMs: 1250, Outcome: OutcomeMet},
+					{Package: PackageInfo{DisplayName: "b@1.0.0"}, ExpectedPolicy: formats.SecurityHigh, DurationMs: 500, Outcome: OutcomeGap},
+				},
+			},
+			{
+				Format: "pypi", Repository: "pypi-proxy", StartedAt: started, FinishedAt: started.Add(time.Minute),
+				Results: []Result{
+					{Package: PackageInfo{DisplayName: "c@1.0.0"}, ExpectedPolicy: formats.SecurityCritical, DurationMs: 2000, Outcome: OutcomeMet},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (JUnitReporter{}).Write(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	var got junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Time != "3.750" {
+		t.Errorf("testsuites time = %s, want 3.750", got.Time)
+	}
+	if len(got.Suites) != 2 || got.Suites[0].Time != "1.750" || got.Suites[1].Time != "2.000" {
+		t.Fatalf("testsuite times = %+v", got.Suites)
+	}
+	if cases := got.Suites[0].TestCases; len(cases) != 2 || cases[0].Time != "1.250" || cases[1].Time != "0.500" {
+		t.Errorf("testcase times = %+v", cases)
+	}
+	if got.Failures != 1 || got.Tests != 3 {
+		t.Errorf("tests = %d, failures = %d", got.Tests, got.Failures)
+	}
+}
@@ -0,0 +1,127 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// MarkdownReporter writes a Report as GitHub flavoured Markdown. In compact mode only results that did not
+// meet expectations are listed, so that the report fits in a pull request or issue comment
+type MarkdownReporter struct {
+	Compact bool
+}
+
+func (m MarkdownReporter) GetName() string {
+	if m.Compact {
+		return "markdown-compact"
+	}
+	return "markdown"
+}
+
+func (m MarkdownReporter) Write(w io.Writer, r *Report) error {
+	var b strings.Builder
+
+	b.WriteString("## Sonatype Repository Firewall Policy Test Report\n\n")
+	fmt.Fprintf(&b, "Tested %s using Sonatype IQ Server %s - %s (%s).\n\n",
+		r.NxrmURL, r.IqURL, r.StartedAt.UTC().Format("2006-01-02 15:04 MST"), r.FinishedAt.Sub(r.StartedAt).Round(time.Second),
+	)
+
+	b.WriteString("### Test Summary\n\n")
+	b.WriteString("| Format | Repository | Downloadable | Cached | Quarantined | Failure | Gaps | Mismatches | Inconclusive |\n")
+	b.WriteString("| ------ | ---------- | -----------: | -----: | ----------: | ------: | ---: | ---------: | -----------: |\n")
+	totals := Counts{}
+	totalOutcomes := map[Outcome]int{}
+	for _, run := range r.Runs {
+		counts := run.Counts()
+		outcomes := map[Outcome]int{}
+		for _, result := range run.Results {
+			outcomes[result.Outcome]++
+			totalOutcomes[result.Outcome]++
+		}
+		totals.Available += counts.Available
+		totals.Cached += counts.Cached
+		totals.Quarantined += counts.Quarantined
+		totals.Failed += counts.Failed
+		totals.Total += counts.Total
+		fmt.Fprintf(&b, "| %s | %s | %d | %d | %d | %d | %d | %d | %d |\n",
+			markdownCell(run.FormatDisplayName), markdownCell(run.Repository),
+			counts.Available, counts.Cached, counts.Quarantined, counts.Failed,
+			outcomes[OutcomeGap], outcomes[OutcomeMismatch], outcomes[OutcomeInconclusive],
+		)
+	}
+	if len(r.Runs) > 1 {
+		fmt.Fprintf(&b, "| **Total** | | **%d** | **%d** | **%d** | **%d** | **%d** | **%d** | **%d** |\n",
+			totals.Available, totals.Cached, totals.Quarantined, totals.Failed,
+			totalOutcomes[OutcomeGap], totalOutcomes[OutcomeMismatch], totalOutcomes[OutcomeInconclusive],
+		)
+	}
+	b.WriteString("\n")
+
+	if m.Compact {
+		b.WriteString("### Unexpected Results\n\n")
+		if totalOutcomes[OutcomeGap]+totalOutcomes[OutcomeMismatch]+totalOutcomes[OutcomeInconclusive] == 0 {
+			b.WriteString("All expectations met.\n")
+			_, err := io.WriteString(w, b.String())
+			return err
+		}
+	} else {
+		b.WriteString("### Details\n\n")
+	}
+
+	b.WriteString("| Format | Repository | Expected Policy | Package | Status | HTTP | Quarantined By | Outcome |\n")
+	b.WriteString("| ------ | ---------- | --------------- | ------- | ------ | ---: | -------------- | ------- |\n")
+	for _, run := range r.Runs {
+		for _, result := range run.Results {
+			if m.Compact && result.Outcome == OutcomeMet {
+				continue
+			}
+			pkg := markdownCell(result.Package.DisplayName)
+			if result.QuarantinePageURL != "" {
+				pkg = fmt.Sprintf("[%s](%s)", pkg, result.QuarantinePageURL)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %d | %s | %s |\n",
+				markdownCell(run.FormatDisplayName), markdownCell(run.Repository),
+				markdownCell(string(result.ExpectedPolicy)), pkg, result.Status, result.HTTPCode,
+				markdownCell(strings.Join(result.QuarantinedByPolicies, ", ")), markdownOutcome(result.Outcome),
+			)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownCell escapes text for use in a Markdown table cell
+func markdownCell(text string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(text)
+}
+
+// markdownOutcome highlights outcomes that did not meet expectations
+func markdownOutcome(outcome Outcome) string {
+	switch outcome {
+	case OutcomeGap, OutcomeMismatch:
+		return fmt.Sprintf("**%s**", outcome)
+	case OutcomeInconclusive:
+		return fmt.Sprintf("_%s_", outcome)
+	default:
+		return string(outcome)
+	}
+}
@@ -0,0 +1,176 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+)
+
+// OpenMetricsContentType is the content type of the OpenMetrics text format
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// metricPrefix is prepended to the name of every metric
+const metricPrefix = "nxfw_policy_test_"
+
+// OpenMetricsReporter writes a Report in the OpenMetrics text format, for the Prometheus node exporter's
+// textfile collector or for scraping from daemon mode
+type OpenMetricsReporter struct{}
+
+// packageMetric is a gauge with a value per package
+type packageMetric struct {
+	name  string
+	unit  string
+	help  string
+	value func(result Result) float64
+}
+
+var packageMetrics = []packageMetric{
+	{
+		name: "available",
+		help: "Whether the package could be downloaded (1) or not (0)",
+		value: func(result Result) float64 {
+			return boolValue(result.Status == formats.StatusAvailable || result.Status == formats.StatusCached)
+		},
+	},
+	{
+		name:  "cached",
+		help:  "Whether the package was already cached by the repository, so not evaluated by Sonatype Repository Firewall (1) or not (0)",
+		value: func(result Result) float64 { return boolValue(result.Status == formats.StatusCached) },
+	},
+	{
+		name: "quarantined",
+		help: "Whether the package was quarantined by Sonatype Repository Firewall (1) or not (0)",
+		value: func(result Result) float64 {
+			return boolValue(result.Status == formats.StatusQuarantined || result.Status == formats.StatusQuarantinedOtherPolicy)
+		},
+	},
+	{
+		name:  "quarantined_by_expected_policy",
+		help:  "Whether the expected policy was one of those that quarantined the package (1) or not (0)",
+		value: func(result Result) float64 { return boolValue(result.QuarantinedWithExpectedPolicy) },
+	},
+	{
+		name:  "failed",
+		help:  "Whether the check of the package failed to execute (1) or not (0)",
+		value: func(result Result) float64 { return boolValue(result.Status == formats.StatusFailed) },
+	},
+	{
+		name:  "http_status",
+		help:  "HTTP status code of the download attempt (0 if the request failed)",
+		value: func(result Result) float64 { return float64(result.HTTPCode) },
+	},
+	{
+		name:  "check_duration_seconds",
+		unit:  "seconds",
+		help:  "Time taken to download the package and check its quarantine status",
+		value: func(result Result) float64 { return float64(result.DurationMs) / 1000 },
+	},
+}
+
+func (o OpenMetricsReporter) GetName() string {
+	return "openmetrics"
+}
+
+func (o OpenMetricsReporter) Write(w io.Writer, r *Report) error {
+	var b strings.Builder
+	writeReportMetrics(&b, r)
+	b.WriteString("# EOF\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteDaemon writes the metrics of the last successful run, if there was one, and whether the most recent
+// run failed - as served in daemon mode
+func (o OpenMetricsReporter) WriteDaemon(w io.Writer, lastSuccessful *Report, lastRunFailed bool) error {
+	var b strings.Builder
+	if lastSuccessful != nil {
+		writeReportMetrics(&b, lastSuccessful)
+	}
+	writeMetricHeader(&b, "last_run_failed", "", "Whether the most recent run failed (1), so the other metrics are from an earlier run, or not (0)")
+	fmt.Fprintf(&b, "%slast_run_failed %s\n", metricPrefix, formatFloat(boolValue(lastRunFailed)))
+	b.WriteString("# EOF\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeReportMetrics writes the metrics of every result in the report
+func writeReportMetrics(b *strings.Builder, r *Report) {
+	writeMetricHeader(b, "run_timestamp_seconds", "seconds", "Time the run finished, in seconds since the epoch")
+	fmt.Fprintf(b, "%srun_timestamp_seconds %s\n", metricPrefix, formatFloat(float64(r.FinishedAt.UnixMilli())/1000))
+	writeMetricHeader(b, "run_duration_seconds", "seconds", "Time taken to test each format and Proxy Repository")
+	for _, run := range r.Runs {
+		fmt.Fprintf(b, "%srun_duration_seconds{%s} %s\n",
+			metricPrefix,
+			formatLabels("format", run.Format, "repository", run.Repository),
+			formatFloat(run.FinishedAt.Sub(run.StartedAt).Seconds()),
+		)
+	}
+
+	for _, metric := range packageMetrics {
+		writeMetricHeader(b, metric.name, metric.unit, metric.help)
+		for _, run := range r.Runs {
+			for _, result := range run.Results {
+				fmt.Fprintf(b, "%s%s{%s} %s\n",
+					metricPrefix,
+					metric.name,
+					formatLabels(
+						"format", run.Format,
+						"repository", run.Repository,
+						"policy", string(result.ExpectedPolicy),
+						"package", result.Package.Name,
+						"version", result.Package.Version,
+					),
+					formatFloat(metric.value(result)),
+				)
+			}
+		}
+	}
+}
+
+func writeMetricHeader(b *strings.Builder, name, unit, help string) {
+	fmt.Fprintf(b, "# TYPE %s%s gauge\n", metricPrefix, name)
+	if unit != "" {
+		fmt.Fprintf(b, "# UNIT %s%s %s\n", metricPrefix, name, unit)
+	}
+	fmt.Fprintf(b, "# HELP %s%s %s\n", metricPrefix, name, help)
+}
+
+// formatLabels formats pairs of label names and values, escaped as required by OpenMetrics
+func formatLabels(pairs ...string) string {
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	labels := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		labels = append(labels, fmt.Sprintf(`%s="%s"`, pairs[i], escaper.Replace(pairs[i+1])))
+	}
+	return strings.Join(labels, ",")
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func boolValue(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,112 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report
+
+import "github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+
+// Outcome classifies a result against what was expected of Sonatype Repository Firewall
+type Outcome string
+
+const (
+	OutcomeMet          Outcome = "met"
+	OutcomeGap          Outcome = "gap"
+	OutcomeMismatch     Outcome = "mismatch"
+	OutcomeInconclusive Outcome = "inconclusive"
+)
+
+// Verdict is whether a result was correct
+type Verdict string
+
+const (
+	VerdictPass         Verdict = "PASS"
+	VerdictFail         Verdict = "FAIL"
+	VerdictInconclusive Verdict = "INCONCLUSIVE"
+)
+
+// Verdict returns whether the outcome was correct - a gap or mismatch is a failure
+func (o Outcome) Verdict() Verdict {
+	switch o {
+	case OutcomeMet:
+		return VerdictPass
+	case OutcomeGap, OutcomeMismatch:
+		return VerdictFail
+	default:
+		return VerdictInconclusive
+	}
+}
+
+// Classify determines the outcome of a single result
+func Classify(result formats.CheckResult, format formats.PackageFormat) Outcome {
+	return classify(
+		result.QuarantineDisabled,
+		result.Cached != nil,
+		result.Available,
+		result.Quarantined,
+		result.QuarantinedWithExpectedPolicy,
+		result.Package.GetExpectedAction(),
+		format.GetName(),
+	)
+}
+
+// classifyResult determines the outcome of a result read from a JSON report
+func classifyResult(result Result, run Run) Outcome {
+	return classify(
+		run.Firewall != nil && !run.Firewall.QuarantineEnabled,
+		result.Status == formats.StatusCached,
+		result.Status == formats.StatusAvailable || result.Status == formats.StatusCached,
+		result.Status == formats.StatusQuarantined || result.Status == formats.StatusQuarantinedOtherPolicy,
+		result.QuarantinedWithExpectedPolicy,
+		expectedAction(result),
+		run.Format,
+	)
+}
+
+// expectedAction returns the expected action of a result read from a JSON report
+func expectedAction(result Result) formats.ExpectedAction {
+	if result.ExpectedAction == "" {
+		// Reports before 1.4 did not record the expected action
+		return result.ExpectedPolicy.DefaultExpectedAction()
+	}
+	return result.ExpectedAction
+}
+
+func classify(quarantineDisabled, cached, available, quarantined, withExpectedPolicy bool, expected formats.ExpectedAction, formatName string) Outcome {
+	switch {
+	case quarantineDisabled:
+		// Firewall could not have quarantined the package
+		return OutcomeInconclusive
+	case expected == formats.ExpectNoPolicy:
+		// Nothing in place could have quarantined the package
+		return OutcomeInconclusive
+	case available && cached && expected.BlocksDownload():
+		// Firewall does not re-evaluate packages that were cached before it was enabled
+		return OutcomeInconclusive
+	case available && !expected.BlocksDownload():
+		return OutcomeMet
+	case available:
+		return OutcomeGap
+	case quarantined && !expected.BlocksDownload():
+		return OutcomeMismatch
+	case quarantined && (withExpectedPolicy || formatName == "docker"):
+		// The policy that quarantined a Container Image cannot be determined
+		return OutcomeMet
+	case quarantined:
+		return OutcomeMismatch
+	default:
+		return OutcomeInconclusive
+	}
+}
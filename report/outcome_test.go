@@ -0,0 +1,82 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report
+
+import (
+	"testing"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+)
+
+func TestClassify(t *testing.T) {
+	critical := formats.Package{Name: "bson", Version: "1.0.9", PolicyName: formats.SecurityCritical}
+	warnOnly := formats.Package{Name: "bson", Version: "1.0.9", PolicyName: formats.SecurityCritical, Expected: formats.ExpectWarnOnly}
+	noPolicy := formats.Package{Name: "bson", Version: "1.0.9", PolicyName: formats.SecurityCritical, Expected: formats.ExpectNoPolicy}
+	none := formats.Package{Name: "react", Version: "18.0.0", PolicyName: formats.None}
+	cached := &formats.CachedAsset{}
+
+	tests := []struct {
+		name   string
+		result formats.CheckResult
+		format formats.PackageFormat
+		want   Outcome
+	}{
+		{"quarantined by expected policy", formats.CheckResult{Package: critical, Quarantined: true, QuarantinedWithExpectedPolicy: true}, formats.NPMFormat{}, OutcomeMet},
+		{"quarantined by other policy", formats.CheckResult{Package: critical, Quarantined: true}, formats.NPMFormat{}, OutcomeMismatch},
+		{"container image quarantined by other policy", formats.CheckResult{Package: critical, Quarantined: true}, formats.DockerFormat{}, OutcomeMet},
+		{"downloadable when expected quarantined", formats.CheckResult{Package: critical, Available: true}, formats.NPMFormat{}, OutcomeGap},
+		{"warn only downloadable", formats.CheckResult{Package: warnOnly, Available: true}, formats.NPMFormat{}, OutcomeMet},
+		{"warn only quarantined", formats.CheckResult{Package: warnOnly, Quarantined: true, QuarantinedWithExpectedPolicy: true}, formats.NPMFormat{}, OutcomeMismatch},
+		{"allowed downloadable", formats.CheckResult{Package: none, Available: true}, formats.NPMFormat{}, OutcomeMet},
+		{"allowed quarantined", formats.CheckResult{Package: none, Quarantined: true}, formats.NPMFormat{}, OutcomeMismatch},
+		{"no policy", formats.CheckResult{Package: noPolicy, Available: true}, formats.NPMFormat{}, OutcomeInconclusive},
+		{"cached when expected quarantined", formats.CheckResult{Package: critical, Available: true, Cached: cached}, formats.NPMFormat{}, OutcomeInconclusive},
+		{"cached when allowed", formats.CheckResult{Package: none, Available: true, Cached: cached}, formats.NPMFormat{}, OutcomeMet},
+		{"quarantine disabled", formats.CheckResult{Package: critical, Available: true, QuarantineDisabled: true}, formats.NPMFormat{}, OutcomeInconclusive},
+		{"failed", formats.CheckResult{Package: critical, Failed: true}, formats.NPMFormat{}, OutcomeInconclusive},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Classify(test.result, test.format); got != test.want {
+				t.Errorf("Classify() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestClassifyResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		result Result
+		run    Run
+		want   Outcome
+	}{
+		{"quarantined", Result{ExpectedPolicy: formats.SecurityCritical, ExpectedAction: formats.ExpectQuarantine, Status: formats.StatusQuarantined, QuarantinedWithExpectedPolicy: true}, Run{Format: "npm"}, OutcomeMet},
+		{"quarantined by other policy", Result{ExpectedPolicy: formats.SecurityCritical, ExpectedAction: formats.ExpectQuarantine, Status: formats.StatusQuarantinedOtherPolicy}, Run{Format: "npm"}, OutcomeMismatch},
+		{"available", Result{ExpectedPolicy: formats.SecurityCritical, ExpectedAction: formats.ExpectQuarantine, Status: formats.StatusAvailable}, Run{Format: "npm"}, OutcomeGap},
+		{"cached", Result{ExpectedPolicy: formats.SecurityCritical, ExpectedAction: formats.ExpectQuarantine, Status: formats.StatusCached}, Run{Format: "npm"}, OutcomeInconclusive},
+		{"expected action before 1.4", Result{ExpectedPolicy: formats.None, Status: formats.StatusAvailable}, Run{Format: "npm"}, OutcomeMet},
+		{"quarantine disabled", Result{ExpectedPolicy: formats.SecurityCritical, ExpectedAction: formats.ExpectQuarantine, Status: formats.StatusAvailable}, Run{Format: "npm", Firewall: &Firewall{AuditEnabled: true}}, OutcomeInconclusive},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := classifyResult(test.result, test.run); got != test.want {
+				t.Errorf("classifyResult() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+)
+
+// SchemaVersion is the version of the report structure - see report/schema.json
+const SchemaVersion = "1.10"
+
+// Report describes a complete run of the tool
+type Report struct {
+	SchemaVersion string              `json:"schemaVersion"`
+	Tool          ToolInfo            `json:"tool"`
+	NxrmURL       string              `json:"nxrmUrl"`
+	IqURL         string              `json:"iqUrl"`
+	StartedAt     time.Time           `json:"startedAt"`
+	FinishedAt    time.Time           `json:"finishedAt"`
+	PolicyMapping map[string][]string `json:"policyMapping,omitempty"`
+	Runs          []Run               `json:"runs"`
+	Baseline      *Comparison         `json:"baseline,omitempty"`
+}
+
+// ToolInfo identifies the build of the tool that produced a Report
+type ToolInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+}
+
+// Run holds the results of testing one format against one Proxy Repository
+type Run struct {
+	Format            string    `json:"format"`
+	FormatDisplayName string    `json:"formatDisplayName"`
+	Repository        string    `json:"repository"`
+	StartedAt         time.Time `json:"startedAt"`
+	FinishedAt        time.Time `json:"finishedAt"`
+	Firewall          *Firewall `json:"firewall,omitempty"`
+	Results           []Result  `json:"results"`
+}
+
+// Firewall is whether Sonatype Repository Firewall audited and quarantined the Proxy Repository
+type Firewall struct {
+	AuditEnabled      bool `json:"auditEnabled"`
+	QuarantineEnabled bool `json:"quarantineEnabled"`
+}
+
+// Result is the outcome of checking a single package
+type Result struct {
+	Package                       PackageInfo            `json:"package"`
+	ExpectedPolicy                formats.PolicyName     `json:"expectedPolicy"`
+	IntegrityState                formats.IntegrityState `json:"integrityState,omitempty"`
+	ExpectedAction                formats.ExpectedAction `json:"expectedAction"`
+	URL                           string                 `json:"url"`
+	HTTPCode                      int                    `json:"httpCode"`
+	Status                        formats.ResultStatus   `json:"status"`
+	QuarantinedByPolicies         []string               `json:"quarantinedByPolicies"`
+	QuarantinedWithExpectedPolicy bool                   `json:"quarantinedWithExpectedPolicy"`
+	MatchedPolicy                 string                 `json:"matchedPolicy,omitempty"`
+	QuarantinePageURL             string                 `json:"quarantinePageUrl,omitempty"`
+	Cached                        *Cached                `json:"cached,omitempty"`
+	Released                      *Released              `json:"releasedFromQuarantine,omitempty"`
+	IntegrityTimeline             *IntegrityTimeline     `json:"integrityTimeline,omitempty"`
+	DurationMs                    int64                  `json:"durationMs"`
+	Verdict                       Verdict                `json:"verdict"`
+	Outcome                       Outcome                `json:"-"`
+}
+
+// Cached describes the asset a package was served from, if it was already in the repository
+type Cached struct {
+	Path           string     `json:"path"`
+	BlobCreated    *time.Time `json:"blobCreated,omitempty"`
+	LastDownloaded *time.Time `json:"lastDownloaded,omitempty"`
+}
+
+// Released records that the package was released from quarantine once the run completed
+type Released struct {
+	QuarantineId  string     `json:"quarantineId"`
+	QuarantinedAt *time.Time `json:"quarantinedAt,omitempty"`
+	ReleasedAt    time.Time  `json:"releasedAt"`
+}
+
+// IntegrityTimeline records how a pending package was resolved, with --track-pending
+type IntegrityTimeline struct {
+	QuarantinedAt *time.Time             `json:"quarantinedAt,omitempty"`
+	Resolved      bool                   `json:"resolved"`
+	State         formats.IntegrityState `json:"state"`
+	BlockedForMs  int64                  `json:"blockedForMs"`
+	Events        []IntegrityEvent       `json:"events"`
+}
+
+// IntegrityEvent is a change in the integrity state of a pending package
+type IntegrityEvent struct {
+	At    time.Time              `json:"at"`
+	State formats.IntegrityState `json:"state"`
+}
+
+// newIntegrityTimeline converts the timeline of a tracked package, or returns nil if it was not tracked
+func newIntegrityTimeline(timeline *formats.IntegrityTimeline) *IntegrityTimeline {
+	if timeline == nil {
+		return nil
+	}
+	converted := &IntegrityTimeline{
+		QuarantinedAt: timeline.QuarantinedAt,
+		Resolved:      timeline.Resolved,
+		State:         timeline.State(),
+		BlockedForMs:  timeline.BlockedFor().Milliseconds(),
+		Events:        make([]IntegrityEvent, 0, len(timeline.Events)),
+	}
+	for _, event := range timeline.Events {
+		converted.Events = append(converted.Events, IntegrityEvent{At: event.At, State: event.State})
+	}
+	return converted
+}
+
+// PackageInfo holds the coordinates of a package
+type PackageInfo struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Extension   string `json:"extension,omitempty"`
+	Qualifier   string `json:"qualifier,omitempty"`
+	DisplayName string `json:"displayName"`
+}
+
+// Counts totals the results of a Run in the same way as the terminal Test Summary
+type Counts struct {
+	Available   int `json:"available"`
+	Cached      int `json:"cached"` // Of those available, how many were already cached
+	Quarantined int `json:"quarantined"`
+	Failed      int `json:"failed"`
+	Total       int `json:"total"`
+}
+
+func (c *Counts) add(status formats.ResultStatus) {
+	c.Total++
+	switch status {
+	case formats.StatusAvailable:
+		c.Available++
+	case formats.StatusCached:
+		c.Available++
+		c.Cached++
+	case formats.StatusQuarantined, formats.StatusQuarantinedOtherPolicy:
+		c.Quarantined++
+	case formats.StatusFailed:
+		c.Failed++
+	}
+}
+
+// Counts returns the summary counts for this Run
+func (r Run) Counts() Counts {
+	counts := Counts{}
+	for _, result := range r.Results {
+		counts.add(result.Status)
+	}
+	return counts
+}
+
+// Reporter writes a Report in a particular output format
+type Reporter interface {
+	GetName() string
+	Write(w io.Writer, r *Report) error
+}
+
+// AllReporters lists every supported output format
+var AllReporters = []Reporter{
+	JSONReporter{},
+	JUnitReporter{},
+	SARIFReporter{},
+	HTMLReporter{},
+	MarkdownReporter{},
+	MarkdownReporter{Compact: true},
+	OpenMetricsReporter{},
+}
+
+// New creates an empty Report
+func New(tool ToolInfo, nxrmURL, iqURL string, startedAt time.Time) *Report {
+	return &Report{
+		SchemaVersion: SchemaVersion,
+		Tool:          tool,
+		NxrmURL:       nxrmURL,
+		IqURL:         iqURL,
+		StartedAt:     startedAt,
+		Runs:          []Run{},
+	}
+}
+
+// AddRun records the results of testing a format against a Proxy Repository
+func (r *Report) AddRun(format formats.PackageFormat, repoName string, firewall formats.FirewallStatus, results []formats.CheckResult, startedAt, finishedAt time.Time) {
+	run := Run{
+		Format:            format.GetName(),
+		FormatDisplayName: format.GetDisplayName(),
+		Repository:        repoName,
+		StartedAt:         startedAt,
+		FinishedAt:        finishedAt,
+		Results:           make([]Result, 0, len(results)),
+	}
+	if firewall.Known {
+		run.Firewall = &Firewall{AuditEnabled: firewall.AuditEnabled, QuarantineEnabled: firewall.QuarantineEnabled}
+	}
+
+	for _, result := range results {
+		outcome := Classify(result, format)
+		var cached *Cached
+		if result.Cached != nil {
+			cached = &Cached{Path: result.Cached.Path, BlobCreated: result.Cached.BlobCreated, LastDownloaded: result.Cached.LastDownloaded}
+		}
+		var released *Released
+		if result.Released != nil {
+			released = &Released{QuarantineId: result.Released.QuarantineId, QuarantinedAt: result.Released.QuarantinedAt, ReleasedAt: result.Released.ReleasedAt}
+		}
+		policies := result.QuarantinedByPolicies
+		if policies == nil {
+			policies = []string{}
+		}
+		run.Results = append(run.Results, Result{
+			Package: PackageInfo{
+				Name:        result.Package.Name,
+				Version:     result.Package.Version,
+				Extension:   result.Package.Extension,
+				Qualifier:   result.Package.Qualifier,
+				DisplayName: format.FormatPackageName(result.Package),
+			},
+			ExpectedPolicy:                result.Package.PolicyName,
+			IntegrityState:                result.Package.Integrity,
+			ExpectedAction:                result.Package.GetExpectedAction(),
+			URL:                           result.URL,
+			HTTPCode:                      result.HTTPCode,
+			Status:                        result.Status(),
+			QuarantinedByPolicies:         policies,
+			QuarantinedWithExpectedPolicy: result.QuarantinedWithExpectedPolicy,
+			MatchedPolicy:                 result.MatchedPolicy,
+			QuarantinePageURL:             result.QuarantinePageURL,
+			Cached:                        cached,
+			Released:                      released,
+			IntegrityTimeline:             newIntegrityTimeline(result.IntegrityTimeline),
+			DurationMs:                    result.Duration.Milliseconds(),
+			Outcome:                       outcome,
+			Verdict:                       outcome.Verdict(),
+		})
+	}
+
+	r.Runs = append(r.Runs, run)
+}
+
+// Load reads a JSON report written by an earlier run
+func Load(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Parse decodes a JSON report
+func Parse(data []byte) (*Report, error) {
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("not a JSON report: %w", err)
+	}
+	major, _, _ := strings.Cut(SchemaVersion, ".")
+	if !strings.HasPrefix(r.SchemaVersion, major+".") {
+		return nil, fmt.Errorf("unsupported schemaVersion '%s' - expected %s.x", r.SchemaVersion, major)
+	}
+
+	// Outcomes are not stored, so are determined again
+	for i := range r.Runs {
+		for j := range r.Runs[i].Results {
+			result := &r.Runs[i].Results[j]
+			result.Outcome = classifyResult(*result, r.Runs[i])
+			result.Verdict = result.Outcome.Verdict()
+		}
+	}
+	return &r, nil
+}
+
+// FindReporter returns the Reporter with the given name
+func FindReporter(name string) (Reporter, error) {
+	var names []string
+	for _, reporter := range AllReporters {
+		if reporter.GetName() == name {
+			return reporter, nil
+		}
+		names = append(names, reporter.GetName())
+	}
+	return nil, fmt.Errorf("unsupported output format '%s' - expected one of: %s", name, strings.Join(names, ", "))
+}
+
+// WriteFile writes the Report using the named Reporter to path
+func (r *Report) WriteFile(reporterName, path string) error {
+	reporter, err := FindReporter(reporterName)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := reporter.Write(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,247 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+)
+
+const (
+	sarifSchema    = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion   = "2.1.0"
+	informationUri = "https://github.com/sonatype-nexus-community/nxfw-policy-tester"
+)
+
+// SARIFReporter writes a Report as SARIF 2.1.0 - one rule per Reference Policy, one result per package
+// that was downloadable when it should have been quarantined, or was quarantined by an unexpected policy
+type SARIFReporter struct{}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+	Properties  map[string]string `json:"properties,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationUri string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name"`
+	ShortDescription     sarifText              `json:"shortDescription"`
+	FullDescription      sarifText              `json:"fullDescription"`
+	Help                 sarifText              `json:"help"`
+	DefaultConfiguration sarifConfiguration     `json:"defaultConfiguration"`
+	Properties           map[string]interface{} `json:"properties"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful bool   `json:"executionSuccessful"`
+	StartTimeUtc        string `json:"startTimeUtc"`
+	EndTimeUtc          string `json:"endTimeUtc"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifText         `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Properties          map[string]string `json:"properties"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+func (s SARIFReporter) GetName() string {
+	return "sarif"
+}
+
+func (s SARIFReporter) Write(w io.Writer, r *Report) error {
+	rules := make([]sarifRule, 0, len(formats.AllPolicyNames))
+	ruleIndex := make(map[formats.PolicyName]int)
+	for i, policy := range formats.AllPolicyNames {
+		ruleIndex[policy] = i
+		rules = append(rules, sarifRule{
+			ID:   sarifRuleID(policy),
+			Name: string(policy),
+			ShortDescription: sarifText{
+				Text: fmt.Sprintf("Repository Firewall did not enforce %s as expected", policy),
+			},
+			FullDescription: sarifText{
+				Text: fmt.Sprintf(
+					"A test package expected to trigger the %s Reference Policy was downloadable through a Proxy Repository, or was quarantined by a different policy.",
+					policy,
+				),
+			},
+			Help: sarifText{
+				Text: "Check the policy exists, applies to the repository and that its Proxy stage action is set to Fail. See https://help.sonatype.com/en/reference-policies.html",
+			},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(policy)},
+			Properties: map[string]interface{}{
+				"security-severity": sarifSecuritySeverity(policy),
+				"tags":              []string{"security", "repository-firewall"},
+			},
+		})
+	}
+
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           r.Tool.Name,
+			Version:        r.Tool.Version,
+			InformationUri: informationUri,
+			Rules:          rules,
+		}},
+		Invocations: []sarifInvocation{{
+			ExecutionSuccessful: true,
+			StartTimeUtc:        r.StartedAt.UTC().Format("2006-01-02T15:04:05.000Z"),
+			EndTimeUtc:          r.FinishedAt.UTC().Format("2006-01-02T15:04:05.000Z"),
+		}},
+		Results: []sarifResult{},
+		Properties: map[string]string{
+			"nxrmUrl": r.NxrmURL,
+			"iqUrl":   r.IqURL,
+			"commit":  r.Tool.Commit,
+		},
+	}
+
+	for _, tested := range r.Runs {
+		for _, result := range tested.Results {
+			var message string
+			switch {
+			case result.Outcome == OutcomeGap:
+				message = fmt.Sprintf(
+					"%s was downloadable from %s, but should have been quarantined by %s",
+					result.Package.DisplayName, tested.Repository, result.ExpectedPolicy,
+				)
+			case result.Outcome == OutcomeMismatch && result.ExpectedAction.BlocksDownload():
+				message = fmt.Sprintf(
+					"%s was quarantined in %s by %s, but was expected to be quarantined by %s",
+					result.Package.DisplayName, tested.Repository, junitPolicies(result), result.ExpectedPolicy,
+				)
+			case result.Outcome == OutcomeMismatch:
+				message = fmt.Sprintf(
+					"%s was quarantined in %s by %s, but was expected to be downloadable (%s)",
+					result.Package.DisplayName, tested.Repository, junitPolicies(result), result.ExpectedAction,
+				)
+			default:
+				continue
+			}
+
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    sarifRuleID(result.ExpectedPolicy),
+				RuleIndex: ruleIndex[result.ExpectedPolicy],
+				Level:     sarifLevel(result.ExpectedPolicy),
+				Message:   sarifText{Text: message},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: result.URL},
+					},
+				}},
+				PartialFingerprints: map[string]string{
+					"nxfwPackage/v1": strings.Join([]string{
+						tested.Format, tested.Repository, result.Package.Name, result.Package.Version, string(result.ExpectedPolicy),
+					}, "/"),
+				},
+				Properties: map[string]string{
+					"format":     tested.Format,
+					"repository": tested.Repository,
+					"status":     string(result.Status),
+				},
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	})
+}
+
+// sarifRuleID returns a rule identifier for a policy, without spaces
+func sarifRuleID(policy formats.PolicyName) string {
+	return "nxfw/" + strings.ReplaceAll(string(policy), " ", "-")
+}
+
+// sarifLevel maps the threat class of a policy to a SARIF level
+func sarifLevel(policy formats.PolicyName) string {
+	switch policy.GetThreatClass() {
+	case formats.ThreatCritical:
+		return "error"
+	case formats.ThreatSevere, formats.ThreatModerate:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifSecuritySeverity maps the threat class of a policy to a CVSS-like score, as used by code scanning dashboards
+func sarifSecuritySeverity(policy formats.PolicyName) string {
+	switch policy.GetThreatClass() {
+	case formats.ThreatCritical:
+		return "9.0"
+	case formats.ThreatSevere:
+		return "7.0"
+	case formats.ThreatModerate:
+		return "5.0"
+	case formats.ThreatLow:
+		return "3.0"
+	default:
+		return "0.0"
+	}
+}
@@ -0,0 +1,600 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/cli"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/config"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/notify"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/nxiq"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/nxrm"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/report"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/util"
+)
+
+// runTarget is a format to be tested against a single Proxy Repository
+type runTarget struct {
+	format     formats.PackageFormat
+	repoName   string
+	firewall   formats.FirewallStatus
+	results    []formats.CheckResult
+	startedAt  time.Time
+	finishedAt time.Time
+}
+
+// runCommand checks packages against Proxy Repositories - taken from a configuration file, flags or prompts
+func runCommand(args []string) {
+	defer cleanupOnPanic()
+
+	flags := newFlagSet("run")
+	configPath := flags.String("config", "", "Run configuration file (YAML or JSON) - see "+config.SchemaURL)
+	nexusURL := flags.String("nxrm-url", "", "Sonatype Nexus Repository URL (e.g. https://nexus.example.com)")
+	nxiqURL := flags.String("nxiq-url", "", "Sonatype IQ Server URL (defaults to the IQ Server connected to Sonatype Nexus Repository)")
+	formatName := flags.String("format", "", "Package format to test (see list-formats)")
+	repoName := flags.String("repository", "", "Name of the Proxy Repository to test")
+	allFormats := flags.Bool("all-formats", false, "Test every supported format against every matching Proxy Repository")
+	allRepositories := flags.Bool("all-repositories", false, "Test every Proxy Repository of the selected format(s)")
+	includeRepos := flags.String("include-repos", "", "With --all-formats/--all-repositories, only test repositories whose name matches this regular expression")
+	excludeRepos := flags.String("exclude-repos", "", "With --all-formats/--all-repositories, do not test repositories whose name matches this regular expression")
+	provision := flags.Bool("provision", false, "Create a temporary Proxy Repository of the format's public registry, with Firewall quarantine enabled, to test instead of an existing one")
+	keepProvisioned := flags.Bool("keep-provisioned", false, "With --provision, do not delete the repository once the run completes")
+	blobStore := flags.String("blob-store", "default", "With --provision, blob store for the repository")
+	purgeCache := flags.Bool("purge-cache", false, "Before checking packages, delete test packages already cached by the repository and invalidate its caches, so Firewall evaluates them afresh")
+	purgeCacheAfter := flags.Bool("purge-cache-after", false, "Once the run completes, delete the test packages cached by the repository and invalidate its caches")
+	trackPendingFlag := flags.Bool("track-pending", false, "Once packages are checked, keep checking Sonatype IQ Server until quarantined Integrity-Rating packages that were pending are released or confirmed quarantined - HuggingFace packages have no known integrity state, so are not tracked")
+	trackPendingInterval := flags.Duration("track-pending-interval", 15*time.Second, "With --track-pending, how long to wait before checking again - doubling each time, up to 5m")
+	trackPendingTimeout := flags.Duration("track-pending-timeout", time.Hour, "With --track-pending, how long to keep checking")
+	releaseAfter := flags.Bool("release-after", false, "Once the run completes, release from quarantine in Sonatype IQ Server the test packages this run caused to be quarantined")
+	assumeYes := flags.Bool("yes", false, "Do not ask for confirmation before checking packages")
+	var outputs outputSpecs
+	flags.Var(&outputs, "output", "Write a report once the run completes, as <format>=<file> (e.g. json=results.json) - may be repeated")
+	baselinePath := flags.String("baseline", "", "JSON report from an earlier run to compare results against")
+	historyFile := flags.String("history-file", "", historyFileUsage)
+	noHistory := flags.Bool("no-history", false, "Do not save this run to the history database")
+	expect := expectSpecs{}
+	flags.Var(expect, "expect", "Override the expected action for a Reference Policy, as <policy>=<action> where action is quarantine, allow or warn-only - may be repeated")
+	policyMapping := make(formats.PolicyMapping)
+	flags.Var(policyNameSpecs{mapping: policyMapping}, "policy-name", "Name of a Sonatype IQ Server policy that takes the place of a Reference Policy, as <policy>=<name> - may be repeated")
+	flags.Var(policyNameSpecs{mapping: policyMapping, pattern: true}, "policy-pattern", "Regular expression matching Sonatype IQ Server policies that take the place of a Reference Policy, as <policy>=<regex> - may be repeated")
+	discover := flags.Bool("discover-policies", false, "Before checking packages, read the policies in Sonatype IQ Server and expect each Reference Policy to quarantine, warn only or have no matching policy")
+	var notifications notifySpecs
+	flags.Var(&notifications, "notify", "Post a summary once the run completes, as <type>=<url> where type is webhook, slack or teams - may be repeated")
+	notifyWhen := flags.String("notify-when", "always", "When to post --notify summaries: always, or only on problems (expectations not met or protection lost since the baseline)")
+	notifyTemplate := flags.String("notify-template", "", "File containing a Go text/template for --notify messages")
+	notifyContentType := flags.String("notify-content-type", "", "Content-Type of webhook bodies rendered from --notify-template (default application/json, which must be valid JSON)")
+	daemon := flags.Bool("daemon", false, "Test repeatedly, serving the latest results as OpenMetrics at /metrics")
+	listen := flags.String("listen", ":9464", "With --daemon, address to serve metrics on")
+	interval := flags.Duration("interval", time.Hour, "With --daemon, time to wait between runs")
+	failOnValue := flags.String("fail-on", "gap,mismatch,inconclusive", "Outcomes that cause a non-zero exit code (comma separated: gap, mismatch, inconclusive)")
+	parseFlags(flags, args)
+
+	failOn, err := parseFailOn(*failOnValue)
+	if err != nil {
+		cli.PrintCliln(fmt.Sprintf("Error: %v", err), util.ColorRed)
+		exit(exitRunError)
+	}
+
+	if *provision && (*configPath != "" || *repoName != "" || *allRepositories) {
+		cli.PrintCliln("Error: --provision cannot be combined with --config, --repository or --all-repositories", util.ColorRed)
+		exit(exitRunError)
+	}
+	if *formatName != "" && (*allFormats || *configPath != "") {
+		cli.PrintCliln("Error: --format cannot be combined with --all-formats or --config", util.ColorRed)
+		exit(exitRunError)
+	}
+	if *repoName != "" && (*allFormats || *allRepositories || *configPath != "") {
+		cli.PrintCliln("Error: --repository cannot be combined with --all-formats, --all-repositories or --config", util.ColorRed)
+		exit(exitRunError)
+	}
+
+	if *configPath != "" && (*allFormats || *allRepositories) {
+		cli.PrintCliln("Error: --all-formats and --all-repositories cannot be combined with --config", util.ColorRed)
+		exit(exitRunError)
+	}
+	if (*includeRepos != "" || *excludeRepos != "") && (!(*allFormats || *allRepositories) || *provision) {
+		cli.PrintCliln("Error: --include-repos and --exclude-repos need --all-formats or --all-repositories, and cannot be combined with --provision", util.ColorRed)
+		exit(exitRunError)
+	}
+
+	repoFilter, err := newRepositoryFilter(*includeRepos, *excludeRepos)
+	if err != nil {
+		cli.PrintCliln(fmt.Sprintf("Error: %v", err), util.ColorRed)
+		exit(exitRunError)
+	}
+
+	when, err := notify.ParseWhen(*notifyWhen)
+	if err != nil {
+		cli.PrintCliln(fmt.Sprintf("Error: %v", err), util.ColorRed)
+		exit(exitRunError)
+	}
+	var tmpl *template.Template
+	if *notifyTemplate != "" {
+		text, err := os.ReadFile(*notifyTemplate)
+		if err == nil {
+			tmpl, err = notify.ParseTemplate(string(text))
+		}
+		if err != nil {
+			cli.PrintCliln(fmt.Sprintf("Error: Invalid --notify-template: %v", err), util.ColorRed)
+			exit(exitRunError)
+		}
+	}
+	for i := range notifications {
+		notifications[i].When = when
+		notifications[i].Template = tmpl
+		notifications[i].ContentType = *notifyContentType
+	}
+
+	var baseline *report.Report
+	if *baselinePath != "" {
+		baseline, err = report.Load(*baselinePath)
+		if err != nil {
+			cli.PrintCliln(fmt.Sprintf("Error: Invalid baseline: %v", err), util.ColorRed)
+			exit(exitRunError)
+		}
+	}
+
+	var runConfig *config.RunConfig
+	var creds credentials
+	expectations := formats.Expectations{Policies: make(map[formats.PolicyName]formats.ExpectedAction)}
+	if *configPath != "" {
+		runConfig, err = config.Load(*configPath)
+		if err != nil {
+			cli.PrintCliln(fmt.Sprintf("Error: Invalid configuration:\n%v", err), util.ColorRed)
+			exit(exitRunError)
+		}
+		if !runConfig.Output.UseColor() {
+			util.DisableColors()
+		}
+		if *nexusURL == "" {
+			*nexusURL = runConfig.Nxrm.URL
+		}
+		if *nxiqURL == "" {
+			*nxiqURL = runConfig.Iq.URL
+		}
+		for _, reportConfig := range runConfig.Output.Reports {
+			outputs = append(outputs, outputSpec{format: reportConfig.Format, path: reportConfig.Path})
+		}
+		expectations = runConfig.Expectations.Expectations()
+		*discover = *discover || runConfig.DiscoverPolicies
+		*purgeCache = *purgeCache || runConfig.PurgeCache
+		for policy, aliases := range runConfig.GetPolicyMapping() {
+			flagAliases := policyMapping[policy]
+			flagAliases.Names = append(flagAliases.Names, aliases.Names...)
+			flagAliases.Patterns = append(flagAliases.Patterns, aliases.Patterns...)
+			policyMapping[policy] = flagAliases
+		}
+		for _, notificationConfig := range runConfig.Notifications {
+			notifications = append(notifications, notificationConfig.Target())
+		}
+		creds = credentials{
+			nxrmUsername: runConfig.Nxrm.Username,
+			nxrmPassword: runConfig.Nxrm.Password,
+			nxiqUsername: runConfig.Iq.Username,
+			nxiqPassword: runConfig.Iq.Password,
+		}
+	}
+
+	printBanner()
+	startedAt := time.Now()
+
+	creds = requireCredentials(creds, true)
+
+	*nexusURL = resolveNexusURL(*nexusURL)
+
+	// Connections
+	nxrmConnection := connectNxrm(*nexusURL, creds)
+	nxiqConnection := connectNxiq(nxrmConnection, strings.TrimSuffix(*nxiqURL, "/"), creds)
+	nxiqConnection.SetPolicyMapping(policyMapping)
+
+	// Determine what to test
+	var targets []runTarget
+	switch {
+	case runConfig != nil:
+		targets = configTargets(runConfig, nxrmConnection)
+	case *provision && *allFormats:
+		targets = provisionTargets(allSupportedFormats, *blobStore, *keepProvisioned, nxrmConnection)
+	case *provision:
+		targets = provisionTargets([]formats.PackageFormat{resolveFormat(*formatName)}, *blobStore, *keepProvisioned, nxrmConnection)
+	case *allFormats:
+		targets = discoverTargets(allSupportedFormats, repoFilter, nxrmConnection)
+	case *allRepositories:
+		targets = discoverTargets([]formats.PackageFormat{resolveFormat(*formatName)}, repoFilter, nxrmConnection)
+	default:
+		targets = []runTarget{promptTarget(*formatName, *repoName, nxrmConnection)}
+	}
+
+	// Flags take precedence over the configuration file
+	for policy, action := range expect {
+		expectations.Policies[policy] = action
+	}
+
+	// Expectations set by hand take precedence over those discovered
+	if *discover {
+		for policy, action := range discoverPolicies(nxiqConnection) {
+			if _, set := expectations.Policies[policy]; !set {
+				expectations.Policies[policy] = action
+			}
+		}
+	}
+	if !expectations.IsEmpty() {
+		for i := range targets {
+			targets[i].format = withExpectations(targets[i].format, expectations)
+		}
+	}
+
+	// Quarantine must be enabled for results to be conclusive
+	firewallStatuses, err := nxrmConnection.GetFirewallStatuses()
+	if err != nil {
+		cli.PrintCliln(fmt.Sprintf("Warning: %v", err), util.ColorYellow)
+	}
+	for i := range targets {
+		targets[i].firewall = nxrm.FirewallStatusOf(firewallStatuses, targets[i].repoName)
+	}
+
+	// Display summary
+	for _, target := range targets {
+		displaySummary(*nexusURL, target.repoName, target.format, target.firewall)
+	}
+
+	// Confirm - a configuration file takes the place of all prompts
+	if runConfig == nil && !*assumeYes && !*daemon {
+		confirmation := cli.ReadInput("Proceed with checking packages? (y/n): ")
+		if !strings.HasPrefix(strings.ToLower(confirmation), "y") {
+			cli.PrintCliln("User cancelled.", util.ColorRed)
+			exit(exitOK)
+		}
+	}
+
+	options := runOptions{
+		baseline:      baseline,
+		outputs:       outputs,
+		notifications: notifications,
+		failOn:        failOn,
+		policyMapping: policyMapping,
+		purgeBefore:   *purgeCache,
+		purgeAfter:    *purgeCacheAfter,
+		releaseAfter:  *releaseAfter,
+		trackPending:  *trackPendingFlag,
+		trackInterval: *trackPendingInterval,
+		trackTimeout:  *trackPendingTimeout,
+		historyFile:   *historyFile,
+		noHistory:     *noHistory,
+	}
+
+	// In daemon mode, test repeatedly and serve the latest results as metrics
+	if *daemon {
+		metrics, err := startMetricsServer(*listen)
+		if err != nil {
+			cli.PrintCliln(fmt.Sprintf("Error: %v", err), util.ColorRed)
+			exit(exitRunError)
+		}
+		for {
+			// A failed run must not stop the exporter - the last successful results are served until the next
+			if runReport, err := executeRun(targets, options, nxrmConnection, nxiqConnection, time.Now()); err != nil {
+				cli.PrintCliln(fmt.Sprintf("Error: Run failed: %v", err), util.ColorRed)
+				metrics.failed()
+			} else {
+				metrics.update(runReport)
+			}
+			waitForNextRun(*interval)
+		}
+	}
+
+	if _, err := executeRun(targets, options, nxrmConnection, nxiqConnection, startedAt); err != nil {
+		cli.PrintCliln(fmt.Sprintf("Error: %v", err), util.ColorRed)
+		exit(exitRunError)
+	}
+
+	exit(determineExitCode(targets, failOn))
+}
+
+// runOptions controls what happens to the results of a run
+type runOptions struct {
+	baseline      *report.Report
+	outputs       outputSpecs
+	notifications notifySpecs
+	failOn        map[report.Outcome]bool
+	policyMapping formats.PolicyMapping
+	purgeBefore   bool
+	purgeAfter    bool
+	releaseAfter  bool
+	trackPending  bool
+	trackInterval time.Duration
+	trackTimeout  time.Duration
+	historyFile   string
+	noHistory     bool
+}
+
+// executeRun checks the packages of every target, then displays, compares, saves and writes reports of
+// the results. An error means the run failed - nothing is exited, so daemon mode can carry on
+func executeRun(targets []runTarget, options runOptions, nxrmConnection *nxrm.NxrmConnection, nxiqConnection *nxiq.NxiqConnection, startedAt time.Time) (*report.Report, error) {
+	// Check packages
+	for i := range targets {
+		if len(targets) > 1 {
+			cli.PrintCliln(fmt.Sprintf("\n>> %s - %s", targets[i].format.GetDisplayName(), targets[i].repoName), util.ColorYellow)
+		}
+		if options.purgeBefore {
+			// Cached packages are served without Firewall evaluation, so results would be misleading
+			if err := purgeTestComponents(nxrmConnection, targets[i]); err != nil {
+				return nil, err
+			}
+		}
+		targets[i].startedAt = time.Now()
+		results, err := nxrmConnection.CheckPackages(targets[i].repoName, targets[i].format, nxiqConnection)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check packages in %s: %w", targets[i].repoName, err)
+		}
+		if options.trackPending {
+			// Before anything is purged or released, which would end the quarantine
+			if err := trackPending(nxiqConnection, targets[i].repoName, targets[i].format, results, options.trackInterval, options.trackTimeout); err != nil {
+				cli.PrintCliln(fmt.Sprintf("Warning: %v", err), util.ColorYellow)
+			}
+		}
+		if options.purgeAfter {
+			if err := purgeTestComponents(nxrmConnection, targets[i]); err != nil {
+				cli.PrintCliln(fmt.Sprintf("Warning: %v", err), util.ColorYellow)
+			}
+		}
+		for j := range results {
+			results[j].QuarantineDisabled = targets[i].firewall.QuarantineDisabled()
+		}
+		if options.releaseAfter {
+			if err := releaseQuarantined(nxiqConnection, targets[i].repoName, results, targets[i].startedAt); err != nil {
+				cli.PrintCliln(fmt.Sprintf("Warning: %v", err), util.ColorYellow)
+			}
+		}
+		targets[i].results = results
+		targets[i].finishedAt = time.Now()
+	}
+
+	// Display results
+	displayCombinedResults(targets)
+
+	runReport := buildReport(targets, nxrmConnection.GetBaseUrl(), nxiqConnection.GetBaseUrl(), startedAt)
+	runReport.PolicyMapping = options.policyMapping.Describe()
+
+	// Compare against the baseline
+	if options.baseline != nil {
+		runReport.Baseline = report.Compare(options.baseline, runReport, provisionedRepositoryKey)
+		displayBaselineComparison(runReport.Baseline)
+	}
+
+	if !options.noHistory {
+		saveHistory(options.historyFile, runReport)
+	}
+
+	// Write reports
+	for _, output := range options.outputs {
+		if err := runReport.WriteFile(output.format, output.path); err != nil {
+			return nil, fmt.Errorf("failed to write %s report to %s: %w", output.format, output.path, err)
+		}
+		cli.PrintCliln(fmt.Sprintf("✓ Wrote %s report to %s", output.format, output.path), util.ColorGreen)
+	}
+
+	sendNotifications(options.notifications, notify.NewSummary(runReport, options.failOn))
+
+	return runReport, nil
+}
+
+// purgeTestComponents deletes the test packages cached by the target's repository and invalidates its caches
+func purgeTestComponents(nxrmConnection *nxrm.NxrmConnection, target runTarget) error {
+	deleted, err := nxrmConnection.PurgeTestComponents(target.repoName, target.format)
+	if err != nil {
+		return err
+	}
+	cli.PrintCliln(fmt.Sprintf("✓ Purged %d cached test component(s) from %s and invalidated its caches", deleted, target.repoName), util.ColorGreen)
+	return nil
+}
+
+// releaseClockSkew allows for the clock of Sonatype IQ Server differing from ours when deciding whether a
+// component was quarantined by this run
+const releaseClockSkew = time.Minute
+
+// releaseQuarantined releases from quarantine the test packages quarantined since the target's check started,
+// recording the release in their results - packages already quarantined by an earlier run are left alone
+func releaseQuarantined(nxiqConnection *nxiq.NxiqConnection, repoName string, results []formats.CheckResult, checkStarted time.Time) error {
+	quarantined, err := nxiqConnection.GetQuarantinedComponents(repoName)
+	if err != nil {
+		return err
+	}
+
+	comment := fmt.Sprintf("Released by %s once its policy test completed", toolName)
+	released, skipped, failures := releaseTestComponents(results, quarantined, checkStarted, time.Now, func(quarantineId string) error {
+		return nxiqConnection.ReleaseFromQuarantine(quarantineId, comment)
+	})
+	for _, err := range failures {
+		cli.PrintCliln(fmt.Sprintf("Warning: %v", err), util.ColorYellow)
+	}
+
+	cli.PrintCliln(fmt.Sprintf("✓ Released %d test component(s) from quarantine in %s", released, repoName), util.ColorGreen)
+	if skipped > 0 {
+		cli.PrintCliln(fmt.Sprintf("  %d test component(s) were quarantined before this run, so were left in quarantine", skipped), util.ColorYellow)
+	}
+	return nil
+}
+
+// releaseTestComponents releases each quarantined result whose component was quarantined no earlier than
+// releaseClockSkew before checkStarted, recording the release in the result. It returns the number released,
+// the number left in quarantine because an earlier run quarantined them, and the releases that failed
+func releaseTestComponents(results []formats.CheckResult, quarantined map[string]nxiq.QuarantinedComponent, checkStarted time.Time, now func() time.Time, release func(quarantineId string) error) (int, int, []error) {
+	released, skipped := 0, 0
+	var failures []error
+	for i := range results {
+		if !results[i].Quarantined || results[i].QuarantineHash == "" {
+			continue
+		}
+		component, ok := quarantined[results[i].QuarantineHash]
+		if !ok {
+			continue
+		}
+		if component.QuarantineTime == nil || component.QuarantineTime.Before(checkStarted.Add(-releaseClockSkew)) {
+			skipped++
+			continue
+		}
+		if err := release(component.QuarantineId); err != nil {
+			failures = append(failures, err)
+			continue
+		}
+		results[i].Released = &formats.QuarantineRelease{
+			QuarantineId:  component.QuarantineId,
+			QuarantinedAt: component.QuarantineTime,
+			ReleasedAt:    now(),
+		}
+		released++
+	}
+	return released, skipped, failures
+}
+
+// sendNotifications posts the summary to every target that wants it - failures are reported but do not
+// fail the run
+func sendNotifications(targets []notify.Target, summary notify.Summary) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	for _, target := range targets {
+		if !target.ShouldNotify(summary) {
+			continue
+		}
+		if err := target.Send(client, summary); err != nil {
+			cli.PrintCliln(fmt.Sprintf("Warning: Failed to send %s notification: %v", target.Kind, err), util.ColorYellow)
+			continue
+		}
+		cli.PrintCliln(fmt.Sprintf("✓ Sent %s notification", target.Kind), util.ColorGreen)
+	}
+}
+
+// buildReport collects the results of every target into a Report
+func buildReport(targets []runTarget, nxrmURL, iqURL string, startedAt time.Time) *report.Report {
+	runReport := report.New(report.ToolInfo{Name: toolName, Version: version, Commit: commit}, nxrmURL, iqURL, startedAt)
+	for _, target := range targets {
+		runReport.AddRun(target.format, target.repoName, target.firewall, target.results, target.startedAt, target.finishedAt)
+	}
+	runReport.FinishedAt = time.Now()
+	return runReport
+}
+
+// repositoryFilter restricts which discovered repositories are tested
+type repositoryFilter struct {
+	include *regexp.Regexp
+	exclude *regexp.Regexp
+}
+
+// newRepositoryFilter compiles the include and exclude expressions - either may be empty
+func newRepositoryFilter(include, exclude string) (repositoryFilter, error) {
+	var filter repositoryFilter
+	var err error
+	if include != "" {
+		if filter.include, err = regexp.Compile(include); err != nil {
+			return filter, fmt.Errorf("invalid --include-repos expression: %w", err)
+		}
+	}
+	if exclude != "" {
+		if filter.exclude, err = regexp.Compile(exclude); err != nil {
+			return filter, fmt.Errorf("invalid --exclude-repos expression: %w", err)
+		}
+	}
+	return filter, nil
+}
+
+// matches returns true if the repository name passes the filter
+func (f repositoryFilter) matches(repoName string) bool {
+	if f.include != nil && !f.include.MatchString(repoName) {
+		return false
+	}
+	if f.exclude != nil && f.exclude.MatchString(repoName) {
+		return false
+	}
+	return true
+}
+
+// discoverTargets returns every Proxy Repository matching the filter for each of the given formats
+func discoverTargets(selectedFormats []formats.PackageFormat, repoFilter repositoryFilter, nxrmConnection *nxrm.NxrmConnection) []runTarget {
+	var targets []runTarget
+	for _, format := range selectedFormats {
+		repos, err := nxrmConnection.GetProxyRepositories(format.GetName())
+		if err != nil {
+			cli.PrintCliln(fmt.Sprintf("Error: %v", err), util.ColorRed)
+			exit(exitRunError)
+		}
+
+		for _, repo := range repos {
+			if repoFilter.matches(repo.GetName()) {
+				targets = append(targets, runTarget{format: format, repoName: repo.GetName()})
+			}
+		}
+	}
+
+	if len(targets) == 0 {
+		cli.PrintCliln("Error: No matching proxy repositories found", util.ColorRed)
+		exit(exitRunError)
+	}
+
+	return targets
+}
+
+// withExpectations applies expectations to the packages of a format
+func withExpectations(format formats.PackageFormat, expectations formats.Expectations) formats.PackageFormat {
+	if filtered, ok := format.(formats.FilteredFormat); ok {
+		filtered.Expectations = expectations
+		return filtered
+	}
+	return formats.FilteredFormat{PackageFormat: format, Expectations: expectations}
+}
+
+// promptTarget returns the format and repository given as flags, prompting for any not supplied
+func promptTarget(formatName, repoName string, nxrmConnection *nxrm.NxrmConnection) runTarget {
+	// Select package format
+	format := resolveFormat(formatName)
+
+	// Select Repository
+	var err error
+	if repoName == "" {
+		repoName, err = nxrmConnection.SelectRepository(format.GetName())
+	} else {
+		err = nxrmConnection.ValidateRepository(format.GetName(), repoName)
+	}
+	if err != nil {
+		cli.PrintCliln(fmt.Sprintf("Error: %v", err), util.ColorRed)
+		exit(exitRunError)
+	}
+
+	return runTarget{format: format, repoName: repoName}
+}
+
+// configTargets returns every format and repository described in the run configuration
+func configTargets(runConfig *config.RunConfig, nxrmConnection *nxrm.NxrmConnection) []runTarget {
+	var targets []runTarget
+	for _, targetConfig := range runConfig.Targets {
+		format := resolveFormat(targetConfig.Format)
+		filtered := formats.FilteredFormat{PackageFormat: format, Filter: targetConfig.PackageFilter()}
+
+		for _, repoName := range targetConfig.Repositories {
+			if err := nxrmConnection.ValidateRepository(format.GetName(), repoName); err != nil {
+				cli.PrintCliln(fmt.Sprintf("Error: %v", err), util.ColorRed)
+				exit(exitRunError)
+			}
+			targets = append(targets, runTarget{format: filtered, repoName: repoName})
+		}
+	}
+	return targets
+}
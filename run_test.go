@@ -0,0 +1,121 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/nxiq"
+)
+
+func TestReleaseTestComponents(t *testing.T) {
+	checkStarted := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	releasedAt := checkStarted.Add(5 * time.Minute)
+	at := func(offset time.Duration) *time.Time {
+		when := checkStarted.Add(offset)
+		return &when
+	}
+
+	tests := []struct {
+		name         string
+		quarantined  bool
+		hash         string
+		component    *nxiq.QuarantinedComponent
+		releaseErr   error
+		wantReleased bool
+		wantSkipped  bool
+		wantFailed   bool
+	}{
+		{"quarantined by this run", true, "a", &nxiq.QuarantinedComponent{QuarantineId: "q-a", QuarantineTime: at(time.Second)}, nil, true, false, false},
+		{"quarantined within the clock skew", true, "a", &nxiq.QuarantinedComponent{QuarantineId: "q-a", QuarantineTime: at(-releaseClockSkew + time.Second)}, nil, true, false, false},
+		{"quarantined at the clock skew", true, "a", &nxiq.QuarantinedComponent{QuarantineId: "q-a", QuarantineTime: at(-releaseClockSkew)}, nil, true, false, false},
+		{"quarantined by an earlier run", true, "a", &nxiq.QuarantinedComponent{QuarantineId: "q-a", QuarantineTime: at(-releaseClockSkew - time.Second)}, nil, false, true, false},
+		{"quarantine time unknown", true, "a", &nxiq.QuarantinedComponent{QuarantineId: "q-a"}, nil, false, true, false},
+		{"not quarantined", false, "a", &nxiq.QuarantinedComponent{QuarantineId: "q-a", QuarantineTime: at(time.Second)}, nil, false, false, false},
+		{"no hash", true, "", &nxiq.QuarantinedComponent{QuarantineId: "q-a", QuarantineTime: at(time.Second)}, nil, false, false, false},
+		{"no longer in quarantine", true, "a", nil, nil, false, false, false},
+		{"release fails", true, "a", &nxiq.QuarantinedComponent{QuarantineId: "q-a", QuarantineTime: at(time.Second)}, errors.New("forbidden"), false, false, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results := []formats.CheckResult{{Quarantined: test.quarantined, QuarantineHash: test.hash}}
+			quarantined := make(map[string]nxiq.QuarantinedComponent)
+			if test.component != nil {
+				quarantined["a"] = *test.component
+			}
+			var releaseIds []string
+			release := func(quarantineId string) error {
+				releaseIds = append(releaseIds, quarantineId)
+				return test.releaseErr
+			}
+
+			released, skipped, failures := releaseTestComponents(results, quarantined, checkStarted, func() time.Time { return releasedAt }, release)
+
+			if (released == 1) != test.wantReleased || (skipped == 1) != test.wantSkipped || (len(failures) == 1) != test.wantFailed {
+				t.Errorf("released %d, skipped %d, failures %v", released, skipped, failures)
+			}
+			if test.wantReleased || test.wantFailed {
+				if !slices.Equal(releaseIds, []string{"q-a"}) {
+					t.Errorf("released %v, want q-a", releaseIds)
+				}
+			} else if len(releaseIds) > 0 {
+				t.Errorf("released %v, want none", releaseIds)
+			}
+
+			record := results[0].Released
+			switch {
+			case test.wantReleased && record == nil:
+				t.Errorf("release not recorded")
+			case test.wantReleased && (record.QuarantineId != "q-a" || record.QuarantinedAt != test.component.QuarantineTime || !record.ReleasedAt.Equal(releasedAt)):
+				t.Errorf("recorded %+v", record)
+			case !test.wantReleased && record != nil:
+				t.Errorf("recorded %+v for a component that was not released", record)
+			}
+		})
+	}
+}
+
+func TestReleaseTestComponentsCounts(t *testing.T) {
+	checkStarted := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	before, after := checkStarted.Add(-time.Hour), checkStarted.Add(time.Minute)
+	results := []formats.CheckResult{
+		{Quarantined: true, QuarantineHash: "new-1"},
+		{Quarantined: true, QuarantineHash: "old"},
+		{Quarantined: true, QuarantineHash: "new-2"},
+		{Available: true},
+	}
+	quarantined := map[string]nxiq.QuarantinedComponent{
+		"new-1": {QuarantineId: "q-1", QuarantineTime: &after},
+		"old":   {QuarantineId: "q-old", QuarantineTime: &before},
+		"new-2": {QuarantineId: "q-2", QuarantineTime: &after},
+	}
+	var releaseIds []string
+	released, skipped, failures := releaseTestComponents(results, quarantined, checkStarted, time.Now, func(quarantineId string) error {
+		releaseIds = append(releaseIds, quarantineId)
+		return nil
+	})
+	if released != 2 || skipped != 1 || len(failures) != 0 {
+		t.Errorf("released %d, skipped %d, failures %v - want 2, 1, none", released, skipped, failures)
+	}
+	if !slices.Equal(releaseIds, []string{"q-1", "q-2"}) {
+		t.Errorf("released %v, want q-1 and q-2 in result order", releaseIds)
+	}
+}
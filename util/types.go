@@ -17,7 +17,7 @@
 package util
 
 // Color codes
-const (
+var (
 	ColorRed     = "\033[0;31m"
 	ColorGreen   = "\033[0;32m"
 	ColorYellow  = "\033[1;33m"
@@ -26,3 +26,14 @@ const (
 	ColorBlue    = "\033[0;34m"
 	ColorReset   = "\033[0m"
 )
+
+// DisableColors removes all colour codes from output
+func DisableColors() {
+	ColorRed = ""
+	ColorGreen = ""
+	ColorYellow = ""
+	ColorCyan = ""
+	ColorMagenta = ""
+	ColorBlue = ""
+	ColorReset = ""
+}
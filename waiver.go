@@ -0,0 +1,288 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/cli"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/nxiq"
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/util"
+)
+
+// waiverExpiry is how long waivers created by test-waiver last if they cannot be deleted
+const waiverExpiry = 24 * time.Hour
+
+// createdWaivers are the waivers created by the round trip, shared with the cleanup that deletes them when the
+// tool exits or is interrupted
+type createdWaivers struct {
+	mu           sync.Mutex
+	repositoryId string
+	ids          map[string]string // Waiver ID by policy name
+}
+
+// add records a waiver created for a policy violation in the repository
+func (w *createdWaivers) add(repositoryId, policyName, waiverId string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ids == nil {
+		w.ids = make(map[string]string)
+	}
+	w.repositoryId = repositoryId
+	w.ids[policyName] = waiverId
+}
+
+// count returns the number of waivers not yet deleted
+func (w *createdWaivers) count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.ids)
+}
+
+// deleteAll deletes every waiver not yet deleted, forgetting those that were - the lock is held throughout, so
+// a cleanup waits for a deletion already under way
+func (w *createdWaivers) deleteAll(deleteWaiver func(repositoryId, waiverId string) error) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var errs []error
+	deleted := 0
+	for policyName, waiverId := range w.ids {
+		if err := deleteWaiver(w.repositoryId, waiverId); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delete(w.ids, policyName)
+		deleted++
+	}
+	if len(errs) > 0 {
+		return deleted, fmt.Errorf("%w - please delete the waiver(s) manually", errors.Join(errs...))
+	}
+	return deleted, nil
+}
+
+// waiverStep is the outcome and timing of one step of the waiver round trip
+type waiverStep struct {
+	name     string
+	err      error
+	skipped  bool
+	detail   string
+	duration time.Duration
+}
+
+// waiverRoundTrip runs the steps of the round trip in order - once a step fails, the rest are skipped
+type waiverRoundTrip struct {
+	steps  []waiverStep
+	failed bool
+}
+
+// step runs a step, timing it and printing its outcome, unless an earlier step failed
+func (w *waiverRoundTrip) step(name string, run func() (string, error)) {
+	if w.failed {
+		w.steps = append(w.steps, waiverStep{name: name, skipped: true})
+		return
+	}
+	w.cleanup(name, run)
+}
+
+// cleanup runs a step even if an earlier step failed
+func (w *waiverRoundTrip) cleanup(name string, run func() (string, error)) {
+	cli.PrintCliln(fmt.Sprintf("%s...", name), util.ColorReset)
+	started := time.Now()
+	detail, err := run()
+	step := waiverStep{name: name, err: err, detail: detail, duration: time.Since(started)}
+	w.steps = append(w.steps, step)
+	if err != nil {
+		w.failed = true
+		cli.PrintCliln(fmt.Sprintf("✗ %v", err), util.ColorRed)
+		return
+	}
+	cli.PrintCliln(fmt.Sprintf("✓ %s", detail), util.ColorGreen)
+}
+
+// waiverCommand proves that waiving a quarantined test package releases it: it waives the policy violations
+// that quarantined the package, waits for Firewall to release it, downloads it again and deletes the waivers
+func waiverCommand(args []string) {
+	defer cleanupOnPanic()
+
+	flags := newFlagSet("test-waiver")
+	nexusURL := flags.String("nxrm-url", "", "Sonatype Nexus Repository URL (e.g. https://nexus.example.com)")
+	nxiqURL := flags.String("nxiq-url", "", "Sonatype IQ Server URL (defaults to the IQ Server connected to Sonatype Nexus Repository)")
+	formatName := flags.String("format", "", "Package format to test (see list-formats)")
+	repoName := flags.String("repository", "", "Name of the Proxy Repository to test")
+	packageName := flags.String("package", "", "Test package to waive, as displayed (e.g. bson@1.0.9) - defaults to the first expected to be quarantined")
+	timeout := flags.Duration("timeout", 5*time.Minute, "How long to wait for Firewall to release the package once waived")
+	pollInterval := flags.Duration("poll-interval", 10*time.Second, "How often to check whether the package has been released")
+	parseFlags(flags, args)
+
+	creds := requireCredentials(credentials{}, true)
+	nxrmConnection := connectNxrm(resolveNexusURL(*nexusURL), creds)
+	nxiqConnection := connectNxiq(nxrmConnection, strings.TrimSuffix(*nxiqURL, "/"), creds)
+	target := promptTarget(*formatName, *repoName, nxrmConnection)
+	if target.format.GetName() == "docker" {
+		cli.PrintCliln("Error: Waivers for Container Images cannot be tested", util.ColorRed)
+		exit(exitRunError)
+	}
+	pkg, err := waiverPackage(target.format, *packageName)
+	if err != nil {
+		cli.PrintCliln(fmt.Sprintf("Error: %v", err), util.ColorRed)
+		exit(exitRunError)
+	}
+	displayName := target.format.FormatPackageName(pkg)
+	url := target.format.ConstructURL(nxrmConnection.GetBaseUrl(), target.repoName, pkg)
+
+	// Waivers are deleted however the tool exits
+	handleInterrupts()
+	waivers := &createdWaivers{}
+	onExit(func() {
+		if _, err := waivers.deleteAll(nxiqConnection.DeleteWaiver); err != nil {
+			cli.PrintCliln(fmt.Sprintf("Warning: %v", err), util.ColorYellow)
+		}
+	})
+	var component nxiq.QuarantinedComponent
+
+	cli.PrintCliln(fmt.Sprintf("\n=== Waiver round trip: %s in %s ===\n", displayName, target.repoName), util.ColorYellow)
+	roundTrip := &waiverRoundTrip{}
+
+	roundTrip.step("Download, expecting quarantine", func() (string, error) {
+		httpCode, err := nxrmConnection.DownloadPackageAtUrl(url)
+		if err != nil {
+			return "", err
+		}
+		if httpCode != http.StatusForbidden {
+			return "", fmt.Errorf("expected HTTP %d, got %d - the package must be quarantined to test a waiver", http.StatusForbidden, httpCode)
+		}
+		return fmt.Sprintf("HTTP %d", httpCode), nil
+	})
+
+	roundTrip.step("Find the policy violations that quarantined it", func() (string, error) {
+		status, err := nxiqConnection.RetrieveFWQuarantineStatus(pkg.Name, pkg.Version, target.repoName, string(pkg.PolicyName), target.format.GetName(), "")
+		if err != nil {
+			return "", err
+		}
+		if status.Hash == "" {
+			return "", fmt.Errorf("%s is not in the Firewall quarantine list for %s", displayName, target.repoName)
+		}
+		quarantined, err := nxiqConnection.GetQuarantinedComponents(target.repoName)
+		if err != nil {
+			return "", err
+		}
+		var ok bool
+		if component, ok = quarantined[status.Hash]; !ok || len(component.Violations) == 0 {
+			return "", fmt.Errorf("no policy violations found for %s in %s", displayName, target.repoName)
+		}
+		var policies []string
+		for _, violation := range component.Violations {
+			policies = append(policies, violation.PolicyName)
+		}
+		return fmt.Sprintf("Quarantined by %s", strings.Join(policies, ", ")), nil
+	})
+
+	roundTrip.step("Waive the violations", func() (string, error) {
+		comment := fmt.Sprintf("Temporary waiver created by %s to test the waiver round trip", toolName)
+		for _, violation := range component.Violations {
+			waiverId, err := nxiqConnection.WaiveViolation(component.RepositoryId, violation, comment, time.Now().Add(waiverExpiry))
+			if err != nil {
+				return "", err
+			}
+			waivers.add(component.RepositoryId, violation.PolicyName, waiverId)
+		}
+		return fmt.Sprintf("Created %d waiver(s), scoped to %s", waivers.count(), target.repoName), nil
+	})
+
+	roundTrip.step("Wait for Firewall to release it", func() (string, error) {
+		deadline := time.Now().Add(*timeout)
+		for {
+			quarantined, err := nxiqConnection.GetQuarantinedComponents(target.repoName)
+			if err != nil {
+				return "", err
+			}
+			if _, stillQuarantined := quarantined[component.Hash]; !stillQuarantined {
+				return "Released from quarantine", nil
+			}
+			if time.Now().Add(*pollInterval).After(deadline) {
+				return "", fmt.Errorf("still quarantined after %s", *timeout)
+			}
+			time.Sleep(*pollInterval)
+		}
+	})
+
+	roundTrip.step("Download again, expecting success", func() (string, error) {
+		httpCode, err := nxrmConnection.DownloadPackageAtUrl(url)
+		if err != nil {
+			return "", err
+		}
+		if httpCode != http.StatusOK {
+			return "", fmt.Errorf("expected HTTP %d, got %d", http.StatusOK, httpCode)
+		}
+		return fmt.Sprintf("HTTP %d", httpCode), nil
+	})
+
+	if waivers.count() > 0 {
+		roundTrip.cleanup("Delete the waivers", func() (string, error) {
+			deleted, err := waivers.deleteAll(nxiqConnection.DeleteWaiver)
+			return fmt.Sprintf("Deleted %d waiver(s)", deleted), err
+		})
+	}
+
+	displayWaiverRoundTrip(roundTrip.steps)
+	if roundTrip.failed {
+		cli.PrintCliln("✗ Waiving the package did not make it downloadable", util.ColorRed)
+		exit(exitRunError)
+	}
+	cli.PrintCliln("✓ Waiving the package made it downloadable", util.ColorGreen)
+	exit(exitOK)
+}
+
+// waiverPackage returns the named test package, or the first expected to be quarantined
+func waiverPackage(format formats.PackageFormat, packageName string) (formats.Package, error) {
+	for _, pkg := range format.GetPackages() {
+		if packageName == "" && pkg.GetExpectedAction() == formats.ExpectQuarantine {
+			return pkg, nil
+		}
+		if packageName != "" && format.FormatPackageName(pkg) == packageName {
+			return pkg, nil
+		}
+	}
+	if packageName == "" {
+		return formats.Package{}, fmt.Errorf("no %s test package is expected to be quarantined", format.GetDisplayName())
+	}
+	return formats.Package{}, fmt.Errorf("%s is not a %s test package", packageName, format.GetDisplayName())
+}
+
+// displayWaiverRoundTrip displays the outcome and timing of each step
+func displayWaiverRoundTrip(steps []waiverStep) {
+	cli.PrintCliln("\n========================== Waiver Round Trip ===========================", util.ColorYellow)
+	var total time.Duration
+	for _, step := range steps {
+		total += step.duration
+		switch {
+		case step.skipped:
+			cli.PrintCliln(fmt.Sprintf("%-8s %-50s", "SKIPPED", step.name), util.ColorYellow)
+		case step.err != nil:
+			cli.PrintCliln(fmt.Sprintf("%-8s %-50s %10s  %v", "FAILED", step.name, step.duration.Round(time.Millisecond), step.err), util.ColorRed)
+		default:
+			cli.PrintCliln(fmt.Sprintf("%-8s %-50s %10s  %s", "OK", step.name, step.duration.Round(time.Millisecond), step.detail), util.ColorGreen)
+		}
+	}
+	cli.PrintCliln(fmt.Sprintf("%-59s %10s\n", "Total", total.Round(time.Millisecond)), util.ColorYellow)
+}
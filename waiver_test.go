@@ -0,0 +1,155 @@
+/**
+ * Copyright (c) 2019-present Sonatype, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sonatype-nexus-community/nxfw-policy-tester/formats"
+)
+
+func TestWaiverPackage(t *testing.T) {
+	format := formats.NPMFormat{}
+	var firstQuarantined, allowed formats.Package
+	for _, pkg := range format.GetPackages() {
+		if firstQuarantined.Name == "" && pkg.GetExpectedAction() == formats.ExpectQuarantine {
+			firstQuarantined = pkg
+		}
+		if pkg.GetExpectedAction() == formats.ExpectAllow {
+			allowed = pkg
+		}
+	}
+	allAllowed := withExpectations(format, formats.Expectations{
+		Policies: map[formats.PolicyName]formats.ExpectedAction{},
+		Packages: []formats.PackageExpectation{{Action: formats.ExpectAllow}},
+	})
+
+	tests := []struct {
+		name        string
+		format      formats.PackageFormat
+		packageName string
+		want        formats.Package
+		wantErr     string
+	}{
+		{"first expected to be quarantined", format, "", firstQuarantined, ""},
+		{"named", format, format.FormatPackageName(allowed), allowed, ""},
+		{"unknown", format, "left-pad@0.0.1", formats.Package{}, "is not a NPM test package"},
+		{"none expected to be quarantined", allAllowed, "", formats.Package{}, "no NPM test package is expected to be quarantined"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := waiverPackage(test.format, test.packageName)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("waiverPackage() error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("waiverPackage() error = %v", err)
+			}
+			if got.Name != test.want.Name || got.Version != test.want.Version {
+				t.Errorf("waiverPackage() = %s@%s, want %s@%s", got.Name, got.Version, test.want.Name, test.want.Version)
+			}
+		})
+	}
+}
+
+func TestWaiverRoundTripSteps(t *testing.T) {
+	roundTrip := &waiverRoundTrip{}
+	var ran []string
+	run := func(name string, err error) func() (string, error) {
+		return func() (string, error) {
+			ran = append(ran, name)
+			return "done", err
+		}
+	}
+
+	roundTrip.step("one", run("one", nil))
+	roundTrip.step("two", run("two", errors.New("failed")))
+	roundTrip.step("three", run("three", nil))
+	roundTrip.cleanup("clean up", run("clean up", nil))
+
+	if want := []string{"one", "two", "clean up"}; !slices.Equal(ran, want) {
+		t.Errorf("ran %v, want %v", ran, want)
+	}
+	if !roundTrip.failed {
+		t.Error("failed = false, want true")
+	}
+	var outcomes []string
+	for _, step := range roundTrip.steps {
+		switch {
+		case step.skipped:
+			outcomes = append(outcomes, step.name+" skipped")
+		case step.err != nil:
+			outcomes = append(outcomes, step.name+" failed")
+		default:
+			outcomes = append(outcomes, step.name+" ok")
+		}
+	}
+	if want := []string{"one ok", "two failed", "three skipped", "clean up ok"}; !slices.Equal(outcomes, want) {
+		t.Errorf("steps = %v, want %v", outcomes, want)
+	}
+}
+
+func TestCreatedWaiversDeleteAll(t *testing.T) {
+	waivers := &createdWaivers{}
+	waivers.add("repo-1", "Security-Critical", "waiver-1")
+	waivers.add("repo-1", "Security-High", "waiver-2")
+
+	// A waiver that cannot be deleted is kept, so the cleanup tries again
+	deleted, err := waivers.deleteAll(func(repositoryId, waiverId string) error {
+		if repositoryId != "repo-1" {
+			t.Errorf("repositoryId = %s, want repo-1", repositoryId)
+		}
+		if waiverId == "waiver-2" {
+			return errors.New("forbidden")
+		}
+		return nil
+	})
+	if deleted != 1 || err == nil || !strings.Contains(err.Error(), "delete the waiver(s) manually") {
+		t.Errorf("deleteAll() = %d, %v", deleted, err)
+	}
+	if waivers.count() != 1 {
+		t.Errorf("count() = %d, want 1", waivers.count())
+	}
+
+	// The cleanup and the final step may race on Ctrl-C - each waiver is deleted once
+	var mu sync.Mutex
+	var calls []string
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = waivers.deleteAll(func(_, waiverId string) error {
+				mu.Lock()
+				defer mu.Unlock()
+				calls = append(calls, waiverId)
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	if want := []string{"waiver-2"}; !slices.Equal(calls, want) {
+		t.Errorf("deleted %v, want %v", calls, want)
+	}
+}